@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	formFlags       []string
+	urlencodedFlags []string
+	bodyTypeFlag    string
+	contentTypeFlag string
+)
+
+// bodyContentTypes maps --body-type values to the Content-Type they send.
+var bodyContentTypes = map[string]string{
+	"json": "application/json",
+	"text": "text/plain; charset=utf-8",
+	"xml":  "application/xml",
+	"raw":  "application/octet-stream",
+}
+
+// addBodyFlags registers the body-shaping flags of post, put and patch.
+func addBodyFlags(c *cobra.Command) {
+	c.Flags().StringArrayVarP(&formFlags, "form", "F", nil, `Multipart form field, repeatable ("name=value", "name=@file", "name=@file;type=image/png")`)
+	c.Flags().StringArrayVar(&urlencodedFlags, "form-urlencoded", nil, `URL-encoded form field, repeatable ("name=value")`)
+	c.Flags().StringVar(&bodyTypeFlag, "body-type", "json", "Body type: json (validated), text, xml or raw")
+	c.Flags().StringVar(&contentTypeFlag, "content-type", "", "Content-Type to send; skips JSON validation")
+}
+
+// requestPayload is the resolved body of a post, put or patch request.
+type requestPayload struct {
+	body        string
+	form        []internal.FormPart
+	contentType string // empty leaves the default (application/json)
+}
+
+// apply sets the body on opts, adding a Content-Type header unless one was
+// given with --headers.
+func (p requestPayload) apply(opts *internal.RequestOptions) {
+	opts.Body = p.body
+	opts.Form = p.form
+	if p.contentType == "" || hasHeader(opts.Headers, "Content-Type") {
+		return
+	}
+	if opts.Headers == nil {
+		opts.Headers = map[string]string{}
+	}
+	opts.Headers["Content-Type"] = p.contentType
+}
+
+// hasHeader reports whether headers sets name, ignoring case.
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestBody resolves the body of a post, put or patch request from its
+// --body value and the body flags, prompting for a JSON body when nothing
+// is given. Everything is interpolated from the environment.
+//
+// --body accepts "@file" to read the body from a file and "-" to read it
+// from stdin.
+func requestBody(bodyValue string) (requestPayload, error) {
+	form, err := internal.ParseFormParts(formFlags)
+	if err != nil {
+		return requestPayload{}, err
+	}
+
+	given := 0
+	for _, set := range []bool{bodyValue != "", len(form) > 0, len(urlencodedFlags) > 0} {
+		if set {
+			given++
+		}
+	}
+	if given > 1 {
+		return requestPayload{}, fmt.Errorf("--body, --form and --form-urlencoded cannot be combined")
+	}
+
+	if len(form) > 0 {
+		return requestPayload{form: interpolateForm(form)}, nil
+	}
+	if len(urlencodedFlags) > 0 {
+		body, err := urlencodedBody(urlencodedFlags)
+		if err != nil {
+			return requestPayload{}, err
+		}
+		return requestPayload{body: body, contentType: "application/x-www-form-urlencoded"}, nil
+	}
+
+	contentType, err := bodyContentType(bodyTypeFlag, contentTypeFlag)
+	if err != nil {
+		return requestPayload{}, err
+	}
+	bodyType := strings.ToLower(bodyTypeFlag)
+
+	body, err := readBodyArg(bodyValue)
+	if err != nil {
+		return requestPayload{}, err
+	}
+	if body == "" && bodyValue == "" {
+		if body, err = internal.ReadBodyInteractive(); err != nil {
+			return requestPayload{}, err
+		}
+	}
+	body = Env.Interpolate(body)
+
+	if body != "" && bodyType == "json" && contentTypeFlag == "" {
+		if err := internal.ValidateJSON(body); err != nil {
+			return requestPayload{}, err
+		}
+	}
+	return requestPayload{body: body, contentType: contentType}, nil
+}
+
+// bodyContentType returns the Content-Type selected by --body-type and
+// --content-type, or "" for JSON, which is sent by default.
+func bodyContentType(bodyType, contentType string) (string, error) {
+	ct, ok := bodyContentTypes[strings.ToLower(bodyType)]
+	if !ok {
+		return "", fmt.Errorf("invalid --body-type %q (want json, text, xml or raw)", bodyType)
+	}
+	switch {
+	case contentType != "":
+		return contentType, nil
+	case strings.EqualFold(bodyType, "json"):
+		return "", nil
+	}
+	return ct, nil
+}
+
+// readBodyArg returns the body named by a --body value: the contents of a
+// file for "@path", stdin for "-", or the value itself.
+//
+// Both are refused when internal.NoLocalFilesEnv is set.
+func readBodyArg(value string) (string, error) {
+	if value == "-" || strings.HasPrefix(value, "@") {
+		if err := internal.CheckLocalFileAccess("--body " + value); err != nil {
+			return "", err
+		}
+	}
+	switch {
+	case value == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("error reading request body: %w", err)
+		}
+		return string(data), nil
+	case strings.HasPrefix(value, "@"):
+		data, err := os.ReadFile(value[1:])
+		if err != nil {
+			return "", fmt.Errorf("could not read body file: %w", err)
+		}
+		return string(data), nil
+	}
+	return value, nil
+}
+
+// urlencodedBody encodes name=value pairs, in order, after interpolation.
+func urlencodedBody(fields []string) (string, error) {
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		name, value, ok := strings.Cut(f, "=")
+		if !ok || name == "" {
+			return "", fmt.Errorf("invalid --form-urlencoded field %q: expected name=value", f)
+		}
+		parts = append(parts, url.QueryEscape(Env.Interpolate(name))+"="+url.QueryEscape(Env.Interpolate(value)))
+	}
+	return strings.Join(parts, "&"), nil
+}
+
+// interpolateForm applies environment interpolation to form values and
+// file paths.
+func interpolateForm(form []internal.FormPart) []internal.FormPart {
+	if len(form) == 0 {
+		return nil
+	}
+	out := make([]internal.FormPart, len(form))
+	for i, p := range form {
+		p.Value = Env.Interpolate(p.Value)
+		p.File = Env.Interpolate(p.File)
+		out[i] = p
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -21,12 +22,22 @@ var collectionCmd = &cobra.Command{
 // ── collection save ───────────────────────────────────────────────────────────
 
 var (
-	saveNameFlag    string
-	saveMethodFlag  string
-	saveURLFlag     string
-	saveHeadersFlag string
-	saveBodyFlag    string
-	saveAuthFlag    string
+	saveNameFlag     string
+	saveMethodFlag   string
+	saveURLFlag      string
+	saveHeadersFlag  string
+	saveBodyFlag     string
+	saveAuthFlag     string
+	saveTagsFlag     []string
+	saveExpectFlags  []string
+	saveCaptureFlags []string
+	saveFromCurlFlag string
+	saveFormFlags    []string
+	saveQueryFlags   []string
+	saveTimeoutFlag  time.Duration
+
+	saveBodyTypeFlag    string
+	saveContentTypeFlag string
 )
 
 var collectionSaveCmd = &cobra.Command{
@@ -35,29 +46,117 @@ var collectionSaveCmd = &cobra.Command{
 	Example: `  apitester collection save --name login --method POST \
     --url "{{base_url}}/auth/login" \
     --body '{"email":"user@example.com","password":"secret"}' \
-    --auth "{{auth_token}}"`,
+    --auth "{{auth_token}}" --tags auth,smoke \
+    --expect "status == 200" --capture "auth_token=$.token"
+  apitester collection save --name health \
+    --from-curl 'curl -u admin:secret {{base_url}}/health'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if saveNameFlag == "" {
 			return fmt.Errorf("--name is required")
 		}
-		if saveMethodFlag == "" {
-			return fmt.Errorf("--method is required")
+
+		var req internal.SavedRequest
+		if saveFromCurlFlag != "" {
+			opts, err := parseCurlArg(saveFromCurlFlag)
+			if err != nil {
+				return err
+			}
+			req = savedRequestFromOptions(saveNameFlag, opts)
+		} else {
+			if saveMethodFlag == "" {
+				return fmt.Errorf("--method is required")
+			}
+			if saveURLFlag == "" {
+				return fmt.Errorf("--url is required")
+			}
+			req = internal.SavedRequest{Name: saveNameFlag, Timeout: 15 * time.Second}
+		}
+
+		// Explicit flags override whatever the curl command specified.
+		if saveMethodFlag != "" {
+			req.Method = strings.ToUpper(saveMethodFlag)
+			if !internal.ValidMethod(req.Method) {
+				return fmt.Errorf("invalid HTTP method %q", saveMethodFlag)
+			}
+		}
+		if saveURLFlag != "" {
+			req.URL = saveURLFlag
+		}
+		if saveHeadersFlag != "" {
+			if req.Headers == nil {
+				req.Headers = map[string]string{}
+			}
+			for k, v := range parseHeaders(saveHeadersFlag) {
+				req.Headers[k] = v
+			}
+		}
+		if saveBodyFlag != "" {
+			body, err := readBodyArg(saveBodyFlag)
+			if err != nil {
+				return err
+			}
+			req.Body = body
+		}
+		if len(saveQueryFlags) > 0 {
+			query, err := internal.ParseQueryParams(saveQueryFlags)
+			if err != nil {
+				return err
+			}
+			if req.Query == nil {
+				req.Query = map[string]string{}
+			}
+			for name, values := range query {
+				if len(values) > 1 {
+					return fmt.Errorf("query parameter %q given more than once; a saved request keeps one value per name", name)
+				}
+				req.Query[name] = values[0]
+			}
 		}
-		if saveURLFlag == "" {
-			return fmt.Errorf("--url is required")
+		if len(saveFormFlags) > 0 {
+			form, err := internal.ParseFormParts(saveFormFlags)
+			if err != nil {
+				return err
+			}
+			req.Form, req.Body = form, ""
+		}
+		contentType, err := bodyContentType(saveBodyTypeFlag, saveContentTypeFlag)
+		if err != nil {
+			return err
+		}
+		if contentType != "" && len(req.Form) == 0 && !hasHeader(req.Headers, "Content-Type") {
+			if req.Headers == nil {
+				req.Headers = map[string]string{}
+			}
+			req.Headers["Content-Type"] = contentType
+		}
+		if saveAuthFlag != "" {
+			req.Auth = saveAuthFlag
+		}
+		if saveTimeoutFlag < 0 {
+			return fmt.Errorf("--timeout must not be negative")
+		}
+		if saveTimeoutFlag > 0 {
+			req.Timeout = saveTimeoutFlag
 		}
 
-		method := strings.ToUpper(saveMethodFlag)
+		assertions, err := internal.ParseAssertions(saveExpectFlags)
+		if err != nil {
+			return err
+		}
+		captures, err := internal.ParseCaptures(saveCaptureFlags)
+		if err != nil {
+			return err
+		}
 
-		req := internal.SavedRequest{
-			Name:    saveNameFlag,
-			Method:  method,
-			URL:     saveURLFlag,
-			Headers: parseHeaders(saveHeadersFlag),
-			Body:    saveBodyFlag,
-			Auth:    saveAuthFlag,
-			Timeout: 15 * time.Second,
+		req.TLS = req.TLS.Merge(tlsFlags)
+		if retryFlag > 0 {
+			if req.Retry, err = flagRetryPolicy(); err != nil {
+				return err
+			}
 		}
+		req.Tags = saveTagsFlag
+		req.Assertions = assertions
+		req.Captures = captures
 
 		return internal.SaveRequest(req)
 	},
@@ -79,10 +178,10 @@ var collectionListCmd = &cobra.Command{
 			return nil
 		}
 
-		fmt.Printf("%-20s  %-7s  %s\n", "NAME", "METHOD", "URL")
-		fmt.Println(strings.Repeat("─", 70))
+		fmt.Printf("%-20s  %-7s  %-40s  %s\n", "NAME", "METHOD", "URL", "TAGS")
+		fmt.Println(strings.Repeat("─", 90))
 		for _, r := range requests {
-			fmt.Printf("%-20s  %-7s  %s\n", r.Name, r.Method, r.URL)
+			fmt.Printf("%-20s  %-7s  %-40s  %s\n", r.Name, r.Method, r.FullURL(), strings.Join(r.Tags, ","))
 		}
 		return nil
 	},
@@ -90,55 +189,170 @@ var collectionListCmd = &cobra.Command{
 
 // ── collection run ────────────────────────────────────────────────────────────
 
+var (
+	runAllFlag  bool
+	runTagsFlag []string
+)
+
 var collectionRunCmd = &cobra.Command{
-	Use:   "run [name]",
-	Short: "Run a saved request by name",
+	Use:   "run [name|pattern]",
+	Short: "Run saved requests by name, glob pattern, or tag",
+	Long: `Run a single saved request by name, or run several in collection order.
+
+With --all, --tag, or a glob pattern (e.g. "users-*") every matching request is
+sent in turn, a PASS/FAIL line is printed for each, and a summary table is shown
+at the end. The command exits non-zero if any request fails.
+
+A request passes when all of its saved assertions hold, or, if it has none,
+when it returns a status below 400. Values captured by earlier requests are
+available to later ones as {{name}}; use --save-env to write them back to the
+--env file.`,
 	Example: `  apitester collection run login
-  apitester collection run login --env dev.json`,
-	Args: cobra.ExactArgs(1),
+  apitester collection run login --env dev.json
+  apitester collection run --all --env staging.json
+  apitester collection run --tag smoke
+  apitester collection run "users-*"`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		name := args[0]
-		req, err := internal.GetRequest(name)
+		pattern := ""
+		if len(args) == 1 {
+			pattern = args[0]
+		}
+
+		if saveEnvFlag && envFile == "" {
+			return fmt.Errorf("--save-env requires --env")
+		}
+		spec, err := loadOpenAPIFlag()
 		if err != nil {
 			return err
 		}
+		if err := loadCookieJar(true); err != nil {
+			return err
+		}
+		if _, err := flagRetryPolicy(); err != nil {
+			return err
+		}
+		if err := checkTimeoutFlags(); err != nil {
+			return err
+		}
 
-		// Apply environment interpolation to all fields
-		url := Env.Interpolate(req.URL)
-		body := Env.Interpolate(req.Body)
-		auth := Env.Interpolate(req.Auth)
-		headers := make(map[string]string)
-		for k, v := range req.Headers {
-			headers[k] = Env.Interpolate(v)
+		if !runAllFlag && len(runTagsFlag) == 0 {
+			if pattern == "" {
+				return fmt.Errorf("specify a request name, a glob pattern, --tag, or --all")
+			}
+			if !strings.ContainsAny(pattern, "*?[") {
+				return runSingleRequest(cmd, pattern, spec)
+			}
 		}
 
-		timeout := req.Timeout
-		if timeout == 0 {
-			timeout = 15 * time.Second
+		requests, err := internal.MatchRequests(pattern, runTagsFlag)
+		if err != nil {
+			return err
+		}
+		if len(requests) == 0 {
+			return fmt.Errorf("no saved requests match the selection")
 		}
 
-		fmt.Printf("Running %q [%s %s]\n\n", name, req.Method, url)
+		cmd.SilenceUsage = true
 
-		opts := internal.RequestOptions{
-			Method:  req.Method,
-			URL:     url,
-			Headers: headers,
-			Body:    body,
-			Auth:    auth,
-			Timeout: timeout,
+		if dryRunFlag {
+			for _, req := range requests {
+				fmt.Printf("# %s\n", req.Name)
+				printCurl(savedRequestOptions(cmd, req))
+				fmt.Println()
+			}
+			return nil
 		}
 
-		resp, respBody, duration, err := internal.SendRequest(opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Request failed: %v\n", err)
-			return nil
+		results := make([]internal.RunResult, 0, len(requests))
+		start := time.Now()
+		for _, req := range requests {
+			opts := savedRequestOptions(cmd, req)
+			printCurl(opts)
+			resp, respBody, duration, err := internal.SendRequest(opts)
+			result := internal.NewRunResult(req, Env, opts.URL, resp, respBody, duration, err)
+			if spec != nil && err == nil {
+				result.ValidateOpenAPI(spec, resp, respBody)
+			}
+			internal.PrintRunResult(result)
+			results = append(results, result)
+		}
+		saveCookieJar()
+		internal.PrintRunSummary(results, time.Since(start))
+		if err := persistEnv(); err != nil {
+			return err
 		}
 
-		internal.PrintResponse(resp, respBody, duration)
+		failed := 0
+		for _, r := range results {
+			if !r.Passed {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("collection run failed: %d of %d requests failed", failed, len(results))
+		}
 		return nil
 	},
 }
 
+// runSingleRequest sends one saved request, pretty-prints its response, and
+// runs the captures and assertions stored with it.
+func runSingleRequest(cmd *cobra.Command, name string, spec *internal.OpenAPISpec) error {
+	req, err := internal.GetRequest(name)
+	if err != nil {
+		return err
+	}
+
+	opts := savedRequestOptions(cmd, req)
+	if !dryRunFlag {
+		fmt.Printf("Running %q [%s %s]\n\n", name, req.Method, opts.URL)
+	}
+
+	return sendAndCheck(cmd, opts, responseChecks{assertions: req.Assertions, captures: req.Captures, spec: spec})
+}
+
+// savedRequestOptions applies environment interpolation to every field of a
+// saved request and converts it into RequestOptions ready to send.
+func savedRequestOptions(cmd *cobra.Command, req internal.SavedRequest) internal.RequestOptions {
+	headers := make(map[string]string)
+	for k, v := range req.Headers {
+		headers[k] = Env.Interpolate(v)
+	}
+
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = 15 * time.Second
+	}
+
+	query := url.Values{}
+	for name, v := range req.Query {
+		query.Set(name, v)
+	}
+
+	opts := internal.RequestOptions{
+		Method:   req.Method,
+		URL:      internal.WithQuery(Env.Interpolate(req.URL), interpolateQuery(query)),
+		Headers:  headers,
+		Body:     Env.Interpolate(req.Body),
+		Form:     interpolateForm(req.Form),
+		Auth:     Env.Interpolate(req.Auth),
+		Timeout:  timeout,
+		Timeouts: req.Timeouts,
+		TLS:      tlsOptions(req.TLS),
+		Proxy:    proxyOption(),
+		Retry:    retryPolicy(req.Retry),
+
+		NoFollow:     req.NoFollow,
+		MaxRedirects: req.MaxRedirects,
+		KeepAuth:     req.KeepAuth,
+	}
+	applyRedirectFlags(cmd, &opts)
+	applyTimeoutFlags(&opts)
+	applyCookies(&opts)
+	return opts
+}
+
 // ── collection delete ─────────────────────────────────────────────────────────
 
 var collectionDeleteCmd = &cobra.Command{
@@ -155,22 +369,222 @@ var collectionDeleteCmd = &cobra.Command{
 	},
 }
 
+// ── collection import ─────────────────────────────────────────────────────────
+
+var importVarsOutFlag string
+
+var collectionImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import requests from other tools into the collection",
+}
+
+var collectionImportPostmanCmd = &cobra.Command{
+	Use:   "postman [file]",
+	Short: "Import a Postman v2.1 collection export",
+	Long: `Convert a Postman v2.1 collection into saved requests.
+
+Requests inside folders are saved as "Folder/Request" and tagged with their
+folder names. Headers, raw/urlencoded/form-data/GraphQL bodies and bearer, basic and API
+key auth are translated; anything else is skipped and reported. Collection
+variables can be written to an environment file with --vars-out.`,
+	Example: `  apitester collection import postman "My API.postman_collection.json"
+  apitester collection import postman api.json --vars-out dev.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.CheckLocalFileRead(args[0], args[0]); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("could not read %q: %w", args[0], err)
+		}
+
+		imp, err := internal.ImportPostmanCollection(data)
+		if err != nil {
+			return err
+		}
+
+		added, updated, err := internal.SaveRequests(imp.Requests)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Imported %d requests from %q (%d new, %d updated).\n", len(imp.Requests), imp.Name, added, updated)
+
+		if len(imp.Variables) > 0 {
+			if importVarsOutFlag == "" {
+				imp.Warnings = append(imp.Warnings, fmt.Sprintf("%d collection variables were not saved; use --vars-out to write them to an env file", len(imp.Variables)))
+			} else {
+				if err := mergeEnvFile(importVarsOutFlag, imp.Variables); err != nil {
+					return err
+				}
+				fmt.Printf("💾 Wrote %d collection variables to %s\n", len(imp.Variables), importVarsOutFlag)
+			}
+		}
+
+		printImportWarnings(imp.Warnings)
+		return nil
+	},
+}
+
+// mergeEnvFile adds vars to an existing env file (or creates it), keeping any
+// values already present in the file.
+func mergeEnvFile(filename string, vars internal.Env) error {
+	env := internal.Env{}
+	if _, err := os.Stat(filename); err == nil {
+		if env, err = internal.LoadEnv(filename); err != nil {
+			return err
+		}
+	}
+	for k, v := range vars {
+		if _, ok := env[k]; !ok {
+			env[k] = v
+		}
+	}
+	return internal.SaveEnv(filename, env)
+}
+
+// printImportWarnings lists the items an importer could not translate.
+func printImportWarnings(warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Printf("\n⚠️  %d item(s) could not be fully translated:\n", len(warnings))
+	for _, w := range warnings {
+		fmt.Printf("    • %s\n", w)
+	}
+}
+
+// ── collection export ─────────────────────────────────────────────────────────
+
+var (
+	exportFormatFlag string
+	exportOutFlag    string
+	exportNameFlag   string
+	exportTagsFlag   []string
+)
+
+var collectionExportCmd = &cobra.Command{
+	Use:   "export [pattern]",
+	Short: "Export saved requests to Postman, curl, or .http format",
+	Long: `Render saved requests in a format other tools understand:
+
+  postman  Postman v2.1 collection ("Folder/Name" requests become folders)
+  curl     bash script of curl commands; {{var}} becomes a shell variable
+  http     .http file for the VS Code REST Client or JetBrains HTTP Client
+
+An optional glob pattern and --tag limit which requests are exported.`,
+	Example: `  apitester collection export --format postman -o api.postman_collection.json
+  apitester collection export --format curl -o requests.sh
+  apitester collection export --format http --tag smoke > smoke.http`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pattern := ""
+		if len(args) == 1 {
+			pattern = args[0]
+		}
+		requests, err := internal.MatchRequests(pattern, exportTagsFlag)
+		if err != nil {
+			return err
+		}
+		if len(requests) == 0 {
+			return fmt.Errorf("no saved requests to export")
+		}
+
+		var out []byte
+		switch strings.ToLower(exportFormatFlag) {
+		case "postman":
+			if out, err = internal.ExportPostman(exportNameFlag, requests); err != nil {
+				return err
+			}
+		case "curl":
+			out = []byte(internal.ExportCurlScript(requests))
+		case "http":
+			out = []byte(internal.ExportHTTPFile(requests))
+		default:
+			return fmt.Errorf("unknown export format %q (want postman, curl, or http)", exportFormatFlag)
+		}
+
+		skipped := 0
+		for _, r := range requests {
+			if len(r.Assertions) > 0 || len(r.Captures) > 0 {
+				skipped++
+			}
+		}
+		if skipped > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: assertions and captures on %d request(s) are not exported\n", skipped)
+		}
+
+		if exportOutFlag == "" {
+			fmt.Print(string(out))
+			return nil
+		}
+		if err := internal.CheckLocalFileAccess("--out " + exportOutFlag); err != nil {
+			return err
+		}
+		mode := os.FileMode(0644)
+		if strings.ToLower(exportFormatFlag) == "curl" {
+			mode = 0755
+		}
+		if err := os.WriteFile(exportOutFlag, out, mode); err != nil {
+			return fmt.Errorf("could not write %q: %w", exportOutFlag, err)
+		}
+		fmt.Printf("✅ Exported %d requests to %s\n", len(requests), exportOutFlag)
+		return nil
+	},
+}
+
 // ── init ──────────────────────────────────────────────────────────────────────
 
 func init() {
 	// save flags
 	collectionSaveCmd.Flags().StringVar(&saveNameFlag, "name", "", "Unique name for the request (required)")
-	collectionSaveCmd.Flags().StringVar(&saveMethodFlag, "method", "", "HTTP method: GET, POST, PUT, DELETE, PATCH (required)")
+	collectionSaveCmd.Flags().StringVar(&saveMethodFlag, "method", "", "HTTP method: GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS or any other token such as PROPFIND (required)")
 	collectionSaveCmd.Flags().StringVar(&saveURLFlag, "url", "", "Request URL, supports {{variable}} syntax (required)")
 	collectionSaveCmd.Flags().StringVar(&saveHeadersFlag, "headers", "", "Comma-separated headers (key:value,...)")
-	collectionSaveCmd.Flags().StringVar(&saveBodyFlag, "body", "", "JSON body for the request")
+	collectionSaveCmd.Flags().StringVar(&saveBodyFlag, "body", "", "Body for the request; @file reads a file, - reads stdin")
+	collectionSaveCmd.Flags().StringVar(&saveBodyTypeFlag, "body-type", "json", "Body type: json, text, xml or raw; stored as the request's Content-Type")
+	collectionSaveCmd.Flags().StringVar(&saveContentTypeFlag, "content-type", "", "Content-Type header to store with the request")
 	collectionSaveCmd.Flags().StringVar(&saveAuthFlag, "auth", "", "Auth header value")
+	collectionSaveCmd.Flags().StringSliceVar(&saveTagsFlag, "tags", nil, "Comma-separated tags used to select requests in 'collection run --tag'")
+	collectionSaveCmd.Flags().StringArrayVar(&saveExpectFlags, "expect", nil, `Assertion checked on every run, repeatable (e.g. "status == 2xx")`)
+	collectionSaveCmd.Flags().StringArrayVar(&saveCaptureFlags, "capture", nil, "Capture a response value into an env variable on every run, repeatable (name=$.path, name=header:Name, name=cookie:name)")
+	collectionSaveCmd.Flags().StringArrayVarP(&saveQueryFlags, "query", "q", nil, `Query parameter, repeatable ("name=value"); supports {{variable}} syntax`)
+	collectionSaveCmd.Flags().StringArrayVarP(&saveFormFlags, "form", "F", nil, `Multipart form field, repeatable ("name=value", "name=@file;type=mime")`)
+	collectionSaveCmd.Flags().DurationVar(&saveTimeoutFlag, "timeout", 0, "Timeout stored with the request (default 15s)")
+	addTLSFlags(collectionSaveCmd)
+	addRetryFlags(collectionSaveCmd)
+	collectionSaveCmd.Flags().StringVar(&saveFromCurlFlag, "from-curl", "", "Build the request from a curl command (\"-\" reads it from stdin); other flags override it")
+
+	// run flags
+	collectionRunCmd.Flags().BoolVar(&runAllFlag, "all", false, "Run every saved request in collection order")
+	collectionRunCmd.Flags().StringSliceVar(&runTagsFlag, "tag", nil, "Run only requests carrying one of these tags (repeatable)")
+	collectionRunCmd.Flags().BoolVar(&saveEnvFlag, "save-env", false, "Write captured variables back to the --env file")
+	collectionRunCmd.Flags().StringVar(&openapiFlag, "openapi", "", "Validate every response against this OpenAPI 3 spec (YAML or JSON)")
+	addCurlFlags(collectionRunCmd)
+	addTLSFlags(collectionRunCmd)
+	addProxyFlag(collectionRunCmd)
+	addRedirectFlags(collectionRunCmd)
+	addCookieFlags(collectionRunCmd)
+	addRetryFlags(collectionRunCmd)
+	addTimeoutFlags(collectionRunCmd)
+
+	// import flags
+	collectionImportPostmanCmd.Flags().StringVar(&importVarsOutFlag, "vars-out", "", "Write collection variables to this env file (merged with existing values)")
+	collectionImportCmd.AddCommand(collectionImportPostmanCmd)
+
+	// export flags
+	collectionExportCmd.Flags().StringVar(&exportFormatFlag, "format", "postman", "Output format: postman, curl, or http")
+	collectionExportCmd.Flags().StringVarP(&exportOutFlag, "out", "o", "", "Write to this file instead of stdout")
+	collectionExportCmd.Flags().StringVar(&exportNameFlag, "name", "apitester", "Collection name used in the Postman export")
+	collectionExportCmd.Flags().StringSliceVar(&exportTagsFlag, "tag", nil, "Export only requests carrying one of these tags (repeatable)")
 
 	// register sub-commands
 	collectionCmd.AddCommand(collectionSaveCmd)
 	collectionCmd.AddCommand(collectionListCmd)
 	collectionCmd.AddCommand(collectionRunCmd)
 	collectionCmd.AddCommand(collectionDeleteCmd)
+	collectionCmd.AddCommand(collectionImportCmd)
+	collectionCmd.AddCommand(collectionExportCmd)
 
 	// register with root
 	rootCmd.AddCommand(collectionCmd)
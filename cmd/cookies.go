@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cookieJarFlag string
+	cookieFlags   []string
+	noCookiesFlag bool
+
+	// jar is the cookie jar shared by every request in this invocation.
+	jar *internal.CookieJar
+)
+
+// addCookieFlags registers the cookie jar flags on a command that sends
+// requests.
+func addCookieFlags(c *cobra.Command) {
+	c.Flags().StringVar(&cookieJarFlag, "cookie-jar", "", "Keep cookies in this jar file across invocations, Netscape format or .json")
+	c.Flags().StringArrayVar(&cookieFlags, "cookie", nil, `Extra cookie to send, repeatable ("name=value")`)
+	c.Flags().BoolVar(&noCookiesFlag, "no-cookies", false, "Neither send nor store jar cookies")
+}
+
+// loadCookieJar opens the --cookie-jar file for this invocation unless
+// --no-cookies is set. Without --cookie-jar nothing is written to disk; a
+// collection run (inMemory) still shares a jar between its requests.
+func loadCookieJar(inMemory bool) error {
+	if noCookiesFlag || jar != nil {
+		return nil
+	}
+	if cookieJarFlag == "" && !inMemory {
+		return nil
+	}
+	j, err := internal.LoadCookieJar(cookieJarFlag)
+	if err != nil {
+		return err
+	}
+	jar = j
+	return nil
+}
+
+// applyCookies attaches the jar and any --cookie values to opts.
+func applyCookies(opts *internal.RequestOptions) {
+	if jar != nil {
+		opts.Jar = jar
+	}
+	if len(cookieFlags) == 0 {
+		return
+	}
+	if opts.Headers == nil {
+		opts.Headers = map[string]string{}
+	}
+	cookies := strings.Join(cookieFlags, "; ")
+	if existing := opts.Headers["Cookie"]; existing != "" {
+		cookies = existing + "; " + cookies
+	}
+	opts.Headers["Cookie"] = cookies
+}
+
+// saveCookieJar writes any cookies received back to the jar file.
+func saveCookieJar() {
+	if jar == nil {
+		return
+	}
+	if err := jar.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+}
+
+// ── cookies root ──────────────────────────────────────────────────────────────
+
+var cookiesCmd = &cobra.Command{
+	Use:   "cookies",
+	Short: "Inspect and reset the cookie jar",
+	Long: `Requests given --cookie-jar FILE store cookies set by servers in that file and
+send them back on later requests, so session-based APIs work across
+invocations. Files not ending in .json are read and written in Netscape
+(curl -c) format.`,
+}
+
+// ── cookies list ──────────────────────────────────────────────────────────────
+
+var cookiesListCmd = &cobra.Command{
+	Use:   "list [domain]",
+	Short: "List the cookies in the jar",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadCookieJar(false); err != nil {
+			return err
+		}
+
+		var cookies []internal.StoredCookie
+		for _, c := range jar.All() {
+			if len(args) == 0 || c.Domain == args[0] || strings.HasSuffix(c.Domain, "."+args[0]) {
+				cookies = append(cookies, c)
+			}
+		}
+		if len(cookies) == 0 {
+			fmt.Printf("No cookies in %s.\n", jar.Path())
+			return nil
+		}
+
+		internal.PrintCookies(cookies)
+		return nil
+	},
+}
+
+// ── cookies clear ─────────────────────────────────────────────────────────────
+
+var cookiesClearCmd = &cobra.Command{
+	Use:   "clear [domain]",
+	Short: "Remove all cookies, or only those for a domain",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadCookieJar(false); err != nil {
+			return err
+		}
+
+		domain := ""
+		if len(args) == 1 {
+			domain = args[0]
+		}
+		n := jar.Clear(domain)
+		if err := jar.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Removed %d cookie(s) from %s.\n", n, jar.Path())
+		return nil
+	},
+}
+
+// ── init ──────────────────────────────────────────────────────────────────────
+
+func init() {
+	for _, c := range []*cobra.Command{cookiesListCmd, cookiesClearCmd} {
+		c.Flags().StringVar(&cookieJarFlag, "cookie-jar", "", "Cookie jar file (required)")
+		c.MarkFlagRequired("cookie-jar")
+		cookiesCmd.AddCommand(c)
+	}
+	rootCmd.AddCommand(cookiesCmd)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+var curlSaveFlag string
+
+var curlCmd = &cobra.Command{
+	Use:   "curl '<curl command>'",
+	Short: "Send (or save) a request written as a curl command",
+	Long: `Parse a curl command line and send it, or save it to the collection with --save.
+
+Pass the whole command as one quoted argument, or "-" to read it from stdin
+(handy for multi-line commands copied from browser devtools). Supported curl
+options:
+
+  body      -d/--data/--data-raw/--data-binary/--data-urlencode, --json,
+            -F/--form, --form-string
+  headers   -X, -H, -u, -b, -A, -e, -G, -I, --url
+  transport -m, --connect-timeout, -L, --max-redirs, --location-trusted,
+            --compressed, --retry, --retry-delay, -x/--proxy, --proxy-user
+  TLS       -k, --cacert, --cert, --key, --tlsv1.x
+
+Backslash line continuations are honoured. Anything else is skipped with a
+warning.
+
+Values are interpolated from --env before sending; saved requests keep their
+{{placeholders}}.`,
+	Example: `  apitester curl 'curl -X POST https://api.example.com/users -H "Content-Type: application/json" -d "{\"name\":\"alice\"}"'
+  pbpaste | apitester curl -
+  apitester curl 'curl -u admin:secret {{base_url}}/health' --env dev.json --expect "status == 200"
+  apitester curl 'curl https://api.example.com/users' --save list-users`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := parseCurlArg(args[0])
+		if err != nil {
+			return err
+		}
+
+		if curlSaveFlag != "" {
+			req := savedRequestFromOptions(curlSaveFlag, opts)
+			req.TLS = req.TLS.Merge(tlsFlags)
+			if opts.Proxy != "" {
+				fmt.Fprintln(os.Stderr, "⚠️  proxies are not stored with saved requests; set \"apitester.proxy\" in your env file instead")
+			}
+			return internal.SaveRequest(req)
+		}
+
+		if opts.Timeout == 0 {
+			opts.Timeout = 15 * time.Second
+		}
+		opts.URL = Env.Interpolate(opts.URL)
+		opts.Body = Env.Interpolate(opts.Body)
+		opts.Auth = Env.Interpolate(opts.Auth)
+		opts.Form = interpolateForm(opts.Form)
+		for k, v := range opts.Headers {
+			opts.Headers[k] = Env.Interpolate(v)
+		}
+		return executeRequest(cmd, opts)
+	},
+}
+
+// parseCurlArg parses a curl command given on the command line, reading it
+// from stdin when arg is "-", and prints any parse warnings.
+func parseCurlArg(arg string) (internal.RequestOptions, error) {
+	if arg == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return internal.RequestOptions{}, err
+		}
+		arg = string(data)
+	}
+	if strings.TrimSpace(arg) == "" {
+		return internal.RequestOptions{}, fmt.Errorf("empty curl command")
+	}
+
+	opts, warnings, err := internal.ParseCurl(arg)
+	if err != nil {
+		return opts, err
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "⚠️  %s\n", w)
+	}
+	return opts, nil
+}
+
+// savedRequestFromOptions turns parsed request options into a collection
+// entry.
+func savedRequestFromOptions(name string, opts internal.RequestOptions) internal.SavedRequest {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = 15 * time.Second
+	}
+	return internal.SavedRequest{
+		Name:     name,
+		Method:   opts.Method,
+		URL:      opts.URL,
+		Headers:  opts.Headers,
+		Body:     opts.Body,
+		Form:     opts.Form,
+		Auth:     opts.Auth,
+		Timeout:  timeout,
+		Timeouts: opts.Timeouts,
+		TLS:      opts.TLS,
+		Retry:    opts.Retry,
+
+		NoFollow:     opts.NoFollow,
+		MaxRedirects: opts.MaxRedirects,
+		KeepAuth:     opts.KeepAuth,
+	}
+}
+
+func init() {
+	curlCmd.Flags().StringVar(&curlSaveFlag, "save", "", "Save the request to the collection under this name instead of sending it")
+	addRequestFlags(curlCmd)
+	rootCmd.AddCommand(curlCmd)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -13,12 +14,17 @@ var deleteCmd = &cobra.Command{
 	Use:   "delete [URL]",
 	Short: "Send a DELETE request to the specified URL",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		url := args[0]
 		url = Env.Interpolate(url)
 		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 			url = "https://" + url
 		}
+		url, err := withQueryFlags(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
+		}
 
 		headers := parseHeaders(headersFlag)
 		for k, v := range headers {
@@ -33,18 +39,14 @@ var deleteCmd = &cobra.Command{
 			Timeout: 10 * time.Second,
 		}
 
-		resp, body, duration, err := internal.SendRequest(opts)
-		if err != nil {
-			fmt.Println("Error:", err)
-			return
-		}
-
-		internal.PrintResponse(resp, body, duration)
+		return executeRequest(cmd, opts)
 	},
 }
 
 func init() {
 	deleteCmd.Flags().StringVar(&headersFlag, "headers", "", "Comma-separated headers (key:value,key:value)")
 	deleteCmd.Flags().StringVar(&authFlag, "auth", "", "Authorization header (e.g., 'Bearer token' or 'Basic base64')")
+	addQueryFlag(deleteCmd)
+	addRequestFlags(deleteCmd)
 	rootCmd.AddCommand(deleteCmd)
 }
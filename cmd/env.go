@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+// ── env root ──────────────────────────────────────────────────────────────────
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage environment files",
+	Long:  `Create and convert the flat JSON environment files used with --env.`,
+}
+
+// ── env import ────────────────────────────────────────────────────────────────
+
+var envImportOutFlag string
+
+var envImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Convert environments from other tools into --env files",
+}
+
+var envImportPostmanCmd = &cobra.Command{
+	Use:   "postman [file]",
+	Short: "Convert a Postman environment export",
+	Example: `  apitester env import postman "Dev.postman_environment.json" --out dev.json
+  apitester env import postman staging.postman_environment.json > staging.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := internal.CheckLocalFileRead(args[0], args[0]); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("could not read %q: %w", args[0], err)
+		}
+
+		env, warnings, err := internal.ImportPostmanEnvironment(data)
+		if err != nil {
+			return err
+		}
+
+		if envImportOutFlag == "" {
+			out, err := json.MarshalIndent(env, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			for _, w := range warnings {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+			}
+			return nil
+		}
+
+		if err := internal.SaveEnv(envImportOutFlag, env); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Wrote %d variables to %s\n", len(env), envImportOutFlag)
+		printImportWarnings(warnings)
+		return nil
+	},
+}
+
+// ── init ──────────────────────────────────────────────────────────────────────
+
+func init() {
+	envImportPostmanCmd.Flags().StringVarP(&envImportOutFlag, "out", "o", "", "Write the environment to this file instead of stdout")
+	envImportCmd.AddCommand(envImportPostmanCmd)
+
+	envCmd.AddCommand(envImportCmd)
+	rootCmd.AddCommand(envCmd)
+}
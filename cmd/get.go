@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -18,12 +19,17 @@ var getCmd = &cobra.Command{
 	Use:   "get [URL]",
 	Short: "Send a GET request to a URL",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		url := args[0]
 		url = Env.Interpolate(url)
 		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 			url = "https://" + url
 		}
+		url, err := withQueryFlags(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
+		}
 
 		headers := parseHeaders(headersFlag)
 		for k, v := range headers {
@@ -38,19 +44,15 @@ var getCmd = &cobra.Command{
 			Timeout: 10 * time.Second,
 		}
 
-		resp, body, duration, err := internal.SendRequest(opts)
-		if err != nil {
-			fmt.Println("Error:", err)
-			return
-		}
-
-		internal.PrintResponse(resp, body, duration)
+		return executeRequest(cmd, opts)
 	},
 }
 
 func init() {
 	getCmd.Flags().StringVar(&headersFlag, "headers", "", "Comma-separated headers (key:value,key:value)")
 	getCmd.Flags().StringVar(&authFlag, "auth", "", "Authorization header (e.g., 'Bearer token' or 'Basic base64')")
+	addQueryFlag(getCmd)
+	addRequestFlags(getCmd)
 	rootCmd.AddCommand(getCmd)
 }
 
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+var headCmd = &cobra.Command{
+	Use:   "head [URL]",
+	Short: "Send a HEAD request and show the response headers",
+	Long: `Send a HEAD request to the specified URL. The server returns headers only,
+so the status line is followed by every response header instead of a body.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url := args[0]
+		url = Env.Interpolate(url)
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			url = "https://" + url
+		}
+		url, err := withQueryFlags(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
+		}
+
+		headers := parseHeaders(headersFlag)
+		for k, v := range headers {
+			headers[k] = Env.Interpolate(v)
+		}
+
+		opts := internal.RequestOptions{
+			Method:  "HEAD",
+			URL:     url,
+			Headers: headers,
+			Auth:    Env.Interpolate(authFlag),
+			Timeout: 10 * time.Second,
+		}
+
+		return executeRequest(cmd, opts)
+	},
+}
+
+func init() {
+	headCmd.Flags().StringVar(&headersFlag, "headers", "", "Comma-separated headers (key:value,key:value)")
+	headCmd.Flags().StringVar(&authFlag, "auth", "", "Authorization header (e.g., 'Bearer token' or 'Basic base64')")
+	addQueryFlag(headCmd)
+	addRequestFlags(headCmd)
+	rootCmd.AddCommand(headCmd)
+}
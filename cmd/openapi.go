@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+// ── openapi root ──────────────────────────────────────────────────────────────
+
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Work with OpenAPI 3 specifications",
+}
+
+// ── openapi import ────────────────────────────────────────────────────────────
+
+var openapiEnvOutFlag string
+
+var openapiImportCmd = &cobra.Command{
+	Use:   "import [spec.yaml|spec.json]",
+	Short: "Create saved requests from an OpenAPI 3.0/3.1 spec",
+	Long: `Create one saved request per operation in an OpenAPI 3.0 or 3.1 document.
+
+  • the operationId becomes the request name (or method-path if missing)
+  • the server URL becomes {{base_url}}
+  • path parameters become {{param}} placeholders
+  • request bodies are filled from examples or generated from their schemas;
+    multipart file fields upload from {{field_file}}
+  • security schemes map to auth: bearer/OAuth2 → {{token}}/{{access_token}},
+    basic → {{basic_credentials}}, API keys → {{api_key}}
+  • operation tags become request tags
+
+Re-importing overwrites requests with the same name.`,
+	Example: `  apitester openapi import petstore.yaml
+  apitester openapi import api.json --env-out dev.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := internal.LoadOpenAPI(args[0])
+		if err != nil {
+			return err
+		}
+
+		imp := internal.ImportOpenAPI(spec)
+		if len(imp.Requests) == 0 {
+			return fmt.Errorf("no operations found in %q", args[0])
+		}
+
+		added, updated, err := internal.SaveRequests(imp.Requests)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Imported %d operations from %q (%d new, %d updated).\n", len(imp.Requests), imp.Title, added, updated)
+
+		if imp.BaseURL != "" {
+			if openapiEnvOutFlag != "" {
+				if err := mergeEnvFile(openapiEnvOutFlag, internal.Env{"base_url": imp.BaseURL}); err != nil {
+					return err
+				}
+				fmt.Printf("💾 Wrote base_url to %s\n", openapiEnvOutFlag)
+			} else {
+				fmt.Printf("   Set \"base_url\": %q in your env file to use the spec's server.\n", imp.BaseURL)
+			}
+		}
+
+		printImportWarnings(imp.Warnings)
+		return nil
+	},
+}
+
+// ── init ──────────────────────────────────────────────────────────────────────
+
+func init() {
+	openapiImportCmd.Flags().StringVar(&openapiEnvOutFlag, "env-out", "", "Write the spec's server URL as base_url to this env file")
+	openapiCmd.AddCommand(openapiImportCmd)
+
+	rootCmd.AddCommand(openapiCmd)
+}
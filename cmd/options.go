@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	originFlag        string
+	requestMethodFlag string
+)
+
+var optionsCmd = &cobra.Command{
+	Use:   "options [URL]",
+	Short: "Send an OPTIONS request and show the allowed methods and CORS headers",
+	Long: `Send an OPTIONS request to the specified URL and show its Allow header and
+any Access-Control-* (CORS) headers.
+
+--origin and --request-method turn it into a CORS preflight, as a browser
+would send before a cross-origin request.`,
+	Example: `  apitester options https://api.example.com/users
+  apitester options https://api.example.com/users --origin https://app.example.com --request-method PUT`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url := args[0]
+		url = Env.Interpolate(url)
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			url = "https://" + url
+		}
+		url, err := withQueryFlags(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
+		}
+
+		headers := parseHeaders(headersFlag)
+		for k, v := range headers {
+			headers[k] = Env.Interpolate(v)
+		}
+		if originFlag != "" {
+			headers["Origin"] = Env.Interpolate(originFlag)
+		}
+		if requestMethodFlag != "" {
+			headers["Access-Control-Request-Method"] = strings.ToUpper(requestMethodFlag)
+		}
+
+		opts := internal.RequestOptions{
+			Method:  "OPTIONS",
+			URL:     url,
+			Headers: headers,
+			Auth:    Env.Interpolate(authFlag),
+			Timeout: 10 * time.Second,
+		}
+
+		return executeRequest(cmd, opts)
+	},
+}
+
+func init() {
+	optionsCmd.Flags().StringVar(&headersFlag, "headers", "", "Comma-separated headers (key:value,key:value)")
+	optionsCmd.Flags().StringVar(&authFlag, "auth", "", "Authorization header (e.g., 'Bearer token' or 'Basic base64')")
+	optionsCmd.Flags().StringVar(&originFlag, "origin", "", "Origin header for a CORS preflight")
+	optionsCmd.Flags().StringVar(&requestMethodFlag, "request-method", "", "Access-Control-Request-Method for a CORS preflight")
+	addQueryFlag(optionsCmd)
+	addRequestFlags(optionsCmd)
+	rootCmd.AddCommand(optionsCmd)
+}
@@ -18,29 +18,22 @@ var patchCmd = &cobra.Command{
 	Use:   "patch [URL]",
 	Short: "Send a PATCH request to the specified URL",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		url := args[0]
 		url = Env.Interpolate(url)
 		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 			url = "https://" + url
 		}
-
-		body := patchBodyFlag
-		if body == "" {
-			var err error
-			body, err = internal.ReadBodyInteractive()
-			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				return
-			}
+		url, err := withQueryFlags(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
 		}
-		body = Env.Interpolate(body)
 
-		if body != "" {
-			if err := internal.ValidateJSON(body); err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				return
-			}
+		payload, err := requestBody(patchBodyFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
 		}
 
 		headers := parseHeaders(headersFlag)
@@ -52,24 +45,21 @@ var patchCmd = &cobra.Command{
 			Method:  "PATCH",
 			URL:     url,
 			Headers: headers,
-			Body:    body,
 			Auth:    Env.Interpolate(authFlag),
 			Timeout: 15 * time.Second,
 		}
+		payload.apply(&opts)
 
-		resp, respBody, duration, err := internal.SendRequest(opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Request failed: %v\n", err)
-			return
-		}
-
-		internal.PrintResponse(resp, respBody, duration)
+		return executeRequest(cmd, opts)
 	},
 }
 
 func init() {
 	patchCmd.Flags().StringVar(&headersFlag, "headers", "", "Comma-separated headers (key:value,key:value)")
 	patchCmd.Flags().StringVar(&authFlag, "auth", "", "Authorization header (e.g., 'Bearer token' or 'Basic base64')")
-	patchCmd.Flags().StringVar(&patchBodyFlag, "body", "", "JSON body for the request")
+	patchCmd.Flags().StringVar(&patchBodyFlag, "body", "", "Request body (JSON unless --body-type/--content-type say otherwise); @file reads a file, - reads stdin")
+	addBodyFlags(patchCmd)
+	addQueryFlag(patchCmd)
+	addRequestFlags(patchCmd)
 	rootCmd.AddCommand(patchCmd)
 }
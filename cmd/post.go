@@ -18,29 +18,22 @@ var postCmd = &cobra.Command{
 	Use:   "post [URL]",
 	Short: "Send a POST request to the specified URL",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		url := args[0]
 		url = Env.Interpolate(url)
 		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 			url = "https://" + url
 		}
-
-		body := bodyFlag
-		if body == "" {
-			var err error
-			body, err = internal.ReadBodyInteractive()
-			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				return
-			}
+		url, err := withQueryFlags(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
 		}
-		body = Env.Interpolate(body)
 
-		if body != "" {
-			if err := internal.ValidateJSON(body); err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				return
-			}
+		payload, err := requestBody(bodyFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
 		}
 
 		headers := parseHeaders(headersFlag)
@@ -52,24 +45,21 @@ var postCmd = &cobra.Command{
 			Method:  "POST",
 			URL:     url,
 			Headers: headers,
-			Body:    body,
 			Auth:    Env.Interpolate(authFlag),
 			Timeout: 15 * time.Second,
 		}
+		payload.apply(&opts)
 
-		resp, respBody, duration, err := internal.SendRequest(opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Request failed: %v\n", err)
-			return
-		}
-
-		internal.PrintResponse(resp, respBody, duration)
+		return executeRequest(cmd, opts)
 	},
 }
 
 func init() {
 	postCmd.Flags().StringVar(&headersFlag, "headers", "", "Comma-separated headers (key:value,key:value)")
 	postCmd.Flags().StringVar(&authFlag, "auth", "", "Authorization header (e.g., 'Bearer token' or 'Basic base64')")
-	postCmd.Flags().StringVar(&bodyFlag, "body", "", "JSON body for the request")
+	postCmd.Flags().StringVar(&bodyFlag, "body", "", "Request body (JSON unless --body-type/--content-type say otherwise); @file reads a file, - reads stdin")
+	addBodyFlags(postCmd)
+	addQueryFlag(postCmd)
+	addRequestFlags(postCmd)
 	rootCmd.AddCommand(postCmd)
 }
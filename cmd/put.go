@@ -18,29 +18,22 @@ var putCmd = &cobra.Command{
 	Use:   "put [URL]",
 	Short: "Send a PUT request to the specified URL",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		url := args[0]
 		url = Env.Interpolate(url)
 		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 			url = "https://" + url
 		}
-
-		body := putBodyFlag
-		if body == "" {
-			var err error
-			body, err = internal.ReadBodyInteractive()
-			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				return
-			}
+		url, err := withQueryFlags(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
 		}
-		body = Env.Interpolate(body)
 
-		if body != "" {
-			if err := internal.ValidateJSON(body); err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				return
-			}
+		payload, err := requestBody(putBodyFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
 		}
 
 		headers := parseHeaders(headersFlag)
@@ -52,24 +45,21 @@ var putCmd = &cobra.Command{
 			Method:  "PUT",
 			URL:     url,
 			Headers: headers,
-			Body:    body,
 			Auth:    Env.Interpolate(authFlag),
 			Timeout: 15 * time.Second,
 		}
+		payload.apply(&opts)
 
-		resp, respBody, duration, err := internal.SendRequest(opts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Request failed: %v\n", err)
-			return
-		}
-
-		internal.PrintResponse(resp, respBody, duration)
+		return executeRequest(cmd, opts)
 	},
 }
 
 func init() {
 	putCmd.Flags().StringVar(&headersFlag, "headers", "", "Comma-separated headers (key:value,key:value)")
 	putCmd.Flags().StringVar(&authFlag, "auth", "", "Authorization header (e.g., 'Bearer token' or 'Basic base64')")
-	putCmd.Flags().StringVar(&putBodyFlag, "body", "", "JSON body for the request")
+	putCmd.Flags().StringVar(&putBodyFlag, "body", "", "Request body (JSON unless --body-type/--content-type say otherwise); @file reads a file, - reads stdin")
+	addBodyFlags(putCmd)
+	addQueryFlag(putCmd)
+	addRequestFlags(putCmd)
 	rootCmd.AddCommand(putCmd)
 }
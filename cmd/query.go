@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"net/url"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+var queryFlags []string
+
+// addQueryFlag registers -q/--query on a command that takes a URL.
+func addQueryFlag(c *cobra.Command) {
+	c.Flags().StringArrayVarP(&queryFlags, "query", "q", nil, `Query parameter, repeatable ("name=value"); replaces the same name in the URL`)
+}
+
+// withQueryFlags merges the interpolated --query parameters into rawURL.
+func withQueryFlags(rawURL string) (string, error) {
+	query, err := internal.ParseQueryParams(queryFlags)
+	if err != nil {
+		return "", err
+	}
+	return internal.WithQuery(rawURL, interpolateQuery(query)), nil
+}
+
+// interpolateQuery resolves {{variables}} in query names and values.
+func interpolateQuery(query url.Values) url.Values {
+	out := url.Values{}
+	for name, values := range query {
+		for _, v := range values {
+			out.Add(Env.Interpolate(name), Env.Interpolate(v))
+		}
+	}
+	return out
+}
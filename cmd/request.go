@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	expectFlags   []string
+	captureFlags  []string
+	saveEnvFlag   bool
+	openapiFlag   string
+	printCurlFlag bool
+	dryRunFlag    bool
+	verboseFlag   bool
+)
+
+var (
+	requestMethodXFlag string
+	requestBodyFlag    string
+)
+
+var requestCmd = &cobra.Command{
+	Use:   "request -X METHOD [URL]",
+	Short: "Send a request with any HTTP method",
+	Long: `Send a request with any HTTP method token, including extension methods
+such as WebDAV's PROPFIND, MKCOL, COPY, MOVE, LOCK and UNLOCK.
+
+The body is optional. Unlike post, put and patch, no body is prompted for
+when --body is omitted.`,
+	Example: `  apitester request -X PROPFIND https://dav.example.com/files/ --headers "Depth:1" --body @propfind.xml --body-type xml
+  apitester request -X PURGE https://cdn.example.com/assets/app.js
+  apitester request -X POST "{{base_url}}/users" --body '{"name":"alice"}' --env dev.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		method := strings.ToUpper(requestMethodXFlag)
+		if !internal.ValidMethod(method) {
+			fmt.Fprintf(os.Stderr, "invalid HTTP method %q\n", requestMethodXFlag)
+			return nil
+		}
+
+		url := args[0]
+		url = Env.Interpolate(url)
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			url = "https://" + url
+		}
+		url, err := withQueryFlags(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return nil
+		}
+
+		var payload requestPayload
+		if requestBodyFlag != "" || len(formFlags) > 0 || len(urlencodedFlags) > 0 {
+			if payload, err = requestBody(requestBodyFlag); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return nil
+			}
+		}
+
+		headers := parseHeaders(headersFlag)
+		for k, v := range headers {
+			headers[k] = Env.Interpolate(v)
+		}
+
+		opts := internal.RequestOptions{
+			Method:  method,
+			URL:     url,
+			Headers: headers,
+			Auth:    Env.Interpolate(authFlag),
+			Timeout: 15 * time.Second,
+		}
+		payload.apply(&opts)
+
+		return executeRequest(cmd, opts)
+	},
+}
+
+func init() {
+	requestCmd.Flags().StringVarP(&requestMethodXFlag, "method", "X", "GET", "HTTP method, any valid token (e.g. GET, PROPFIND, MKCOL)")
+	requestCmd.Flags().StringVar(&headersFlag, "headers", "", "Comma-separated headers (key:value,key:value)")
+	requestCmd.Flags().StringVar(&authFlag, "auth", "", "Authorization header (e.g., 'Bearer token' or 'Basic base64')")
+	requestCmd.Flags().StringVar(&requestBodyFlag, "body", "", "Optional request body; @file reads a file, - reads stdin")
+	addBodyFlags(requestCmd)
+	addQueryFlag(requestCmd)
+	addRequestFlags(requestCmd)
+	rootCmd.AddCommand(requestCmd)
+}
+
+// addRequestFlags registers the flags shared by every method command.
+func addRequestFlags(c *cobra.Command) {
+	c.Flags().StringArrayVar(&expectFlags, "expect", nil, `Assertion to check against the response, repeatable (e.g. "status == 2xx", "$.id exists", "latency < 500ms")`)
+	c.Flags().StringArrayVar(&captureFlags, "capture", nil, "Capture a response value into an env variable, repeatable (name=$.path, name=header:Name, name=cookie:name)")
+	c.Flags().BoolVar(&saveEnvFlag, "save-env", false, "Write captured variables back to the --env file")
+	c.Flags().StringVar(&openapiFlag, "openapi", "", "Validate the response against this OpenAPI 3 spec (YAML or JSON)")
+	c.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "Show request and response headers and a connection timing breakdown")
+	addCurlFlags(c)
+	addTLSFlags(c)
+	addProxyFlag(c)
+	addRedirectFlags(c)
+	addCookieFlags(c)
+	addRetryFlags(c)
+	addTimeoutFlags(c)
+}
+
+// addCurlFlags registers --print-curl and --dry-run.
+func addCurlFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&printCurlFlag, "print-curl", false, "Print the equivalent curl command before sending")
+	c.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Print the equivalent curl command without sending the request")
+}
+
+// responseChecks groups everything evaluated against a response after it is
+// printed.
+type responseChecks struct {
+	assertions []internal.Assertion
+	captures   []internal.Capture
+	spec       *internal.OpenAPISpec
+}
+
+// empty reports whether there is nothing to check.
+func (c responseChecks) empty() bool {
+	return len(c.assertions) == 0 && len(c.captures) == 0 && c.spec == nil
+}
+
+// executeRequest sends a request built by one of the method commands, prints
+// the response, and runs any --expect, --capture, and --openapi checks.
+func executeRequest(cmd *cobra.Command, opts internal.RequestOptions) error {
+	assertions, err := internal.ParseAssertions(expectFlags)
+	if err != nil {
+		return err
+	}
+	captures, err := internal.ParseCaptures(captureFlags)
+	if err != nil {
+		return err
+	}
+	spec, err := loadOpenAPIFlag()
+	if err != nil {
+		return err
+	}
+	if saveEnvFlag && envFile == "" {
+		return fmt.Errorf("--save-env requires --env")
+	}
+	opts.TLS = tlsOptions(opts.TLS)
+	if p := proxyOption(); p != "" {
+		opts.Proxy = p
+	}
+	applyRedirectFlags(cmd, &opts)
+	if err := checkTimeoutFlags(); err != nil {
+		return err
+	}
+	applyTimeoutFlags(&opts)
+	if _, err := flagRetryPolicy(); err != nil {
+		return err
+	}
+	opts.Retry = retryPolicy(opts.Retry)
+	if err := loadCookieJar(false); err != nil {
+		return err
+	}
+	applyCookies(&opts)
+
+	return sendAndCheck(cmd, opts, responseChecks{assertions: assertions, captures: captures, spec: spec})
+}
+
+// sendAndCheck sends a request, pretty-prints the response, and runs the
+// given checks against it.
+func sendAndCheck(cmd *cobra.Command, opts internal.RequestOptions, checks responseChecks) error {
+	if printCurl(opts) {
+		return nil
+	}
+
+	opts.Trace = &internal.RequestTrace{}
+
+	resp, body, duration, err := internal.SendRequest(opts)
+	saveCookieJar()
+	if err != nil {
+		if verboseFlag {
+			internal.PrintRequestTrace(opts.Trace, opts.Method, opts.URL)
+			internal.PrintTiming(opts.Trace)
+		}
+		internal.PrintRedirects(opts.Trace)
+		fmt.Fprintf(os.Stderr, "Request failed: %v\n", err)
+		if !checks.empty() {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("request failed, response checks not evaluated")
+		}
+		return nil
+	}
+
+	internal.PrintRedirects(opts.Trace)
+	if verboseFlag {
+		internal.PrintRequestTrace(opts.Trace, resp.Request.Method, resp.Request.URL.String())
+		internal.PrintResponseHeaders(resp)
+		if resp.TLS != nil {
+			internal.PrintTLSState(resp.TLS)
+		}
+	}
+	internal.PrintResponse(opts.Method, resp, body, duration)
+	if verboseFlag {
+		internal.PrintTiming(opts.Trace)
+	}
+
+	errs := []error{
+		applyCaptures(checks.captures, resp, body),
+		checkAssertions(checks.assertions, resp, body, duration),
+		validateOpenAPI(checks.spec, resp, body),
+	}
+	if err := errors.Join(errs...); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	return nil
+}
+
+// printCurl prints the curl equivalent of opts when --print-curl or --dry-run
+// is set, and reports whether the request should be skipped.
+func printCurl(opts internal.RequestOptions) bool {
+	if !printCurlFlag && !dryRunFlag {
+		return false
+	}
+	fmt.Println(internal.CurlCommand(opts))
+	if !dryRunFlag {
+		fmt.Println()
+	}
+	return dryRunFlag
+}
+
+// loadOpenAPIFlag loads the spec named by --openapi, if any.
+func loadOpenAPIFlag() (*internal.OpenAPISpec, error) {
+	if openapiFlag == "" {
+		return nil, nil
+	}
+	return internal.LoadOpenAPI(openapiFlag)
+}
+
+// checkAssertions prints the result of each assertion and returns an error
+// if any of them failed.
+func checkAssertions(assertions []internal.Assertion, resp *http.Response, body []byte, duration time.Duration) error {
+	if len(assertions) == 0 {
+		return nil
+	}
+
+	fmt.Println("Assertions:")
+	results := internal.EvaluateAssertions(assertions, resp, body, duration)
+	if failed := internal.PrintAssertionResults(results, "  "); failed > 0 {
+		return fmt.Errorf("%d of %d assertions failed", failed, len(results))
+	}
+	return nil
+}
+
+// applyCaptures stores captured response values in the active environment,
+// printing each one, and persists the environment when --save-env is set.
+func applyCaptures(captures []internal.Capture, resp *http.Response, body []byte) error {
+	if len(captures) == 0 {
+		return nil
+	}
+
+	fmt.Println("Captures:")
+	results := internal.ApplyCaptures(Env, captures, resp, body)
+	failed := internal.PrintCaptureResults(results, "  ")
+	if failed < len(results) {
+		if err := persistEnv(); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d captures failed", failed, len(results))
+	}
+	return nil
+}
+
+// validateOpenAPI checks the response against the spec, printing every
+// violation, and returns an error if there were any.
+func validateOpenAPI(spec *internal.OpenAPISpec, resp *http.Response, body []byte) error {
+	if spec == nil {
+		return nil
+	}
+
+	fmt.Println("OpenAPI validation:")
+	if n := internal.PrintOpenAPIValidation(spec.ValidateResponse(resp, body), "  "); n > 0 {
+		return fmt.Errorf("response violates the OpenAPI spec (%d problem(s))", n)
+	}
+	return nil
+}
+
+// persistEnv writes the active environment back to the --env file when
+// --save-env is set.
+func persistEnv() error {
+	if !saveEnvFlag {
+		return nil
+	}
+	if err := internal.SaveEnv(envFile, Env); err != nil {
+		return err
+	}
+	fmt.Printf("💾 Saved environment to %s\n", envFile)
+	return nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	retryFlag      int
+	retryDelayFlag time.Duration
+	retryOnFlag    string
+)
+
+// addRetryFlags registers --retry, --retry-delay and --retry-on.
+func addRetryFlags(c *cobra.Command) {
+	c.Flags().IntVar(&retryFlag, "retry", 0, "Retry transient failures up to N times")
+	c.Flags().DurationVar(&retryDelayFlag, "retry-delay", time.Second, "Initial delay between retries; doubles each attempt (Retry-After takes precedence)")
+	c.Flags().StringVar(&retryOnFlag, "retry-on", internal.DefaultRetryOn, "Comma-separated conditions to retry: status codes, classes like 5xx, connection-errors")
+}
+
+// flagRetryPolicy builds the retry policy given on the command line. It is
+// the zero policy unless --retry is set.
+func flagRetryPolicy() (internal.RetryPolicy, error) {
+	if retryFlag <= 0 {
+		return internal.RetryPolicy{}, nil
+	}
+	on, err := internal.ParseRetryOn(retryOnFlag)
+	if err != nil {
+		return internal.RetryPolicy{}, err
+	}
+	return internal.RetryPolicy{Max: retryFlag, Delay: retryDelayFlag, On: on}, nil
+}
+
+// retryPolicy returns the command-line policy when --retry is set, or the
+// one saved with a request otherwise.
+func retryPolicy(saved internal.RetryPolicy) internal.RetryPolicy {
+	if retryFlag <= 0 {
+		return saved
+	}
+	p, _ := flagRetryPolicy() // validated when the command starts
+	return p
+}
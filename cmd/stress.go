@@ -18,6 +18,12 @@ var (
 	stressHeadersFlag     string
 	stressAuthFlag        string
 	stressMethodFlag      string
+	stressRateFlag        string
+	stressStagesFlag      string
+	stressMaxInFlightFlag int
+	stressPrecisionFlag   int
+	stressHdrOutFlag      string
+	stressNoProgressFlag  bool
 )
 
 var stressCmd = &cobra.Command{
@@ -26,10 +32,56 @@ var stressCmd = &cobra.Command{
 	Long: `Hammer an API endpoint with concurrent requests to measure its performance.
 
 Reports: total requests, successes, failures, requests/sec, and latency
-percentiles (Min, Max, Avg, P50, P95, P99).`,
+percentiles (Min, Max, Avg, P50, P90, P95, P99, P99.9, P99.99), then a breakdown by status code
+with P50/P95/P99 for each, and of requests that got no response by error
+class: timeout, connection refused, connection reset, tls, dns or body read.
+
+By default --concurrency workers each send their next request as soon as the
+previous one returns, so a slow server also slows the test down. --rate
+instead sends requests at a fixed arrival rate however the server responds,
+with at most --max-in-flight outstanding; requests due while that many are
+pending are dropped and reported, and latency is measured from when each
+request was due rather than when it was actually sent.
+
+--stages ramps the load linearly between targets instead of holding it
+fixed, for warm-up, step, spike and soak tests. Targets are worker counts
+("30s:10,2m:100,30s:0") or arrival rates ("1m:50/s,5m:200/s"); each stage
+moves from the previous target (0 at the start) to its own. A YAML file can
+name the stages:
+
+  stages:
+    - name: warm-up
+      duration: 30s
+      target: 10
+    - name: soak
+      duration: 10m
+      target: 10
+
+The report adds a row per stage.
+
+Latencies are recorded in an HDR histogram, so memory stays flat however
+long the test runs; --hdr-precision sets how many significant digits are
+kept (1-5). Successful and failed requests are recorded separately, so slow
+timeouts don't skew the percentiles. The two overall histograms take about
+400KB together at the default precision of 3, 5MB at 4 and 35MB at 5; the
+per-status and per-stage ones stay at 3 digits (about 200KB per status code
+and 400KB per stage). --hdr-out writes the full percentile
+distribution in HdrHistogram's .hgrm format, for plotting with
+HdrHistogram's plotter and similar tools; failures go to a second file
+ending in .failures.hgrm.
+
+While the test runs, a live panel refreshed every second shows elapsed time,
+current requests/sec, requests in flight, P50/P95/P99 over the last few
+seconds, the error rate and a throughput sparkline. When output is not a
+terminal (the web terminal, CI logs) a plain progress line is printed every
+5 seconds instead. --no-progress turns both off.`,
 	Example: `  apitester stress https://httpbin.org/get --concurrency 20 --duration 15s
   apitester stress https://api.example.com/data --method GET --concurrency 10 --requests 500
-  apitester stress "{{base_url}}/users" --env dev.json --concurrency 30 --duration 30s`,
+  apitester stress "{{base_url}}/users" --env dev.json --concurrency 30 --duration 30s
+  apitester stress https://api.example.com/data --rate 500/s --duration 1m
+  apitester stress https://api.example.com/data --stages 30s:10,2m:100,30s:0
+  apitester stress https://api.example.com/data --stages profile.yaml
+  apitester stress https://api.example.com/data --rate 200/s --duration 5m --hdr-out latency.hgrm`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		url := args[0]
@@ -37,6 +89,19 @@ percentiles (Min, Max, Avg, P50, P95, P99).`,
 		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 			url = "https://" + url
 		}
+		url, err := withQueryFlags(url)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		// Checked up front so a long test doesn't end with nowhere to write.
+		if stressHdrOutFlag != "" {
+			if err := internal.CheckLocalFileAccess("--hdr-out " + stressHdrOutFlag); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return
+			}
+		}
 
 		method := strings.ToUpper(stressMethodFlag)
 
@@ -55,6 +120,11 @@ percentiles (Min, Max, Avg, P50, P95, P99).`,
 			headers[k] = Env.Interpolate(v)
 		}
 
+		if err := checkTimeoutFlags(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
 		// Parse duration
 		duration, err := time.ParseDuration(stressDurationFlag)
 		if err != nil {
@@ -67,6 +137,36 @@ percentiles (Min, Max, Avg, P50, P95, P99).`,
 			duration = 24 * time.Hour // effectively unlimited time; workers stop via counter
 		}
 
+		var rate float64
+		if stressRateFlag != "" {
+			if rate, err = internal.ParseRate(stressRateFlag); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return
+			}
+		}
+		if stressMaxInFlightFlag < 1 {
+			fmt.Fprintln(os.Stderr, "--max-in-flight must be at least 1")
+			return
+		}
+
+		if _, err := internal.NewHistogram(stressPrecisionFlag); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		var stages []internal.Stage
+		if stressStagesFlag != "" {
+			if stressRateFlag != "" || stressRequestsFlag > 0 {
+				fmt.Fprintln(os.Stderr, "--stages cannot be combined with --rate or --requests")
+				return
+			}
+			if stages, err = internal.ParseStages(stressStagesFlag); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return
+			}
+			duration = internal.StagesDuration(stages)
+		}
+
 		opts := internal.StressOptions{
 			Method:      method,
 			URL:         url,
@@ -76,7 +176,19 @@ percentiles (Min, Max, Avg, P50, P95, P99).`,
 			Concurrency: stressConcurrencyFlag,
 			Duration:    duration,
 			MaxRequests: stressRequestsFlag,
-			Timeout:     10 * time.Second,
+			Rate:        rate,
+			MaxInFlight: stressMaxInFlightFlag,
+			Stages:      stages,
+			Precision:   stressPrecisionFlag,
+			Timeout:     requestTimeout(10 * time.Second),
+			Timeouts:    timeoutsFlags,
+			TLS:         tlsOptions(internal.TLSOptions{}),
+			Proxy:       proxyOption(),
+		}
+
+		if err := internal.CheckTransport(opts.TLS, opts.Proxy); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
 		}
 
 		displayDuration := duration
@@ -85,7 +197,18 @@ percentiles (Min, Max, Avg, P50, P95, P99).`,
 		}
 
 		fmt.Printf("🔥 Starting stress test → %s %s\n", method, url)
-		fmt.Printf("   Concurrency: %d  |  ", opts.Concurrency)
+		switch {
+		case len(stages) > 0:
+			steps := make([]string, len(stages))
+			for i, st := range stages {
+				steps[i] = st.String()
+			}
+			fmt.Printf("   Stages: %s  |  ", strings.Join(steps, " → "))
+		case rate > 0:
+			fmt.Printf("   Rate: %s  |  Max In Flight: %d  |  ", internal.FormatRate(rate), opts.MaxInFlight)
+		default:
+			fmt.Printf("   Concurrency: %d  |  ", opts.Concurrency)
+		}
 		if stressRequestsFlag > 0 {
 			fmt.Printf("Max Requests: %d\n", stressRequestsFlag)
 		} else {
@@ -93,8 +216,17 @@ percentiles (Min, Max, Avg, P50, P95, P99).`,
 		}
 		fmt.Println()
 
+		if !stressNoProgressFlag {
+			display := internal.NewStressDisplay(os.Stdout, displayDuration)
+			opts.Progress = display.Update
+		}
+
 		start := time.Now()
-		result := internal.RunStress(opts)
+		result, err := internal.RunStress(opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
 		elapsed := time.Since(start)
 
 		// Patch opts.Duration for the report if --requests was used
@@ -103,17 +235,62 @@ percentiles (Min, Max, Avg, P50, P95, P99).`,
 		}
 
 		internal.PrintStressReport(opts, result)
+
+		if stressHdrOutFlag != "" {
+			if err := writeHistograms(stressHdrOutFlag, result); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return
+			}
+		}
 	},
 }
 
+// writeHistograms saves the latency distributions of a stress test as .hgrm
+// files: successes to path, failures (if any) next to it.
+func writeHistograms(path string, result internal.StressResult) error {
+	if err := writeHistogram(path, result.Latencies); err != nil {
+		return err
+	}
+	if result.Failed.Count() > 0 {
+		return writeHistogram(strings.TrimSuffix(path, ".hgrm")+".failures.hgrm", result.Failed)
+	}
+	return nil
+}
+
+func writeHistogram(path string, h *internal.Histogram) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not write latency histogram: %w", err)
+	}
+	err = h.WritePercentiles(out)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write latency histogram: %w", err)
+	}
+	fmt.Printf("💾 Wrote latency distribution to %s\n", path)
+	return nil
+}
+
 func init() {
 	stressCmd.Flags().IntVar(&stressConcurrencyFlag, "concurrency", 10, "Number of concurrent workers")
 	stressCmd.Flags().StringVar(&stressDurationFlag, "duration", "10s", "Duration of the test (e.g. 10s, 1m, 30s)")
 	stressCmd.Flags().IntVar(&stressRequestsFlag, "requests", 0, "Total number of requests to send (overrides --duration)")
+	stressCmd.Flags().StringVar(&stressRateFlag, "rate", "", "Send requests at a fixed arrival rate instead of a worker pool (e.g. 500/s, 30/m)")
+	stressCmd.Flags().StringVar(&stressStagesFlag, "stages", "", `Ramp load through stages, e.g. "30s:10,2m:100,30s:0" (workers) or "1m:50/s,5m:200/s" (rate), or a YAML profile file`)
+	stressCmd.Flags().IntVar(&stressMaxInFlightFlag, "max-in-flight", 1000, "With --rate or rate stages, maximum outstanding requests; sends beyond it are dropped")
+	stressCmd.Flags().IntVar(&stressPrecisionFlag, "hdr-precision", internal.DefaultHistogramPrecision, "Significant digits kept by the overall latency histograms, 1-5 (about 400KB at 3, 5MB at 4, 35MB at 5)")
+	stressCmd.Flags().StringVar(&stressHdrOutFlag, "hdr-out", "", "Write the latency percentile distribution to this .hgrm file")
+	stressCmd.Flags().BoolVar(&stressNoProgressFlag, "no-progress", false, "Do not show live progress while the test runs")
 	stressCmd.Flags().StringVar(&stressMethodFlag, "method", "GET", "HTTP method to use")
 	stressCmd.Flags().StringVar(&stressBodyFlag, "body", "", "JSON body for each request")
 	stressCmd.Flags().StringVar(&stressHeadersFlag, "headers", "", "Comma-separated headers (key:value,...)")
 	stressCmd.Flags().StringVar(&stressAuthFlag, "auth", "", "Auth header value")
+	addQueryFlag(stressCmd)
+	addTimeoutFlags(stressCmd)
+	addTLSFlags(stressCmd)
+	addProxyFlag(stressCmd)
 
 	rootCmd.AddCommand(stressCmd)
 }
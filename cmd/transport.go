@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/RvShivam/API_tester/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tlsFlags  internal.TLSOptions
+	proxyFlag string
+
+	followFlag       bool
+	noFollowFlag     bool
+	maxRedirectsFlag int
+	keepAuthFlag     bool
+
+	timeoutFlag   time.Duration
+	timeoutsFlags internal.Timeouts
+)
+
+// addTLSFlags registers the TLS options shared by every command that sends
+// requests.
+func addTLSFlags(c *cobra.Command) {
+	c.Flags().StringVar(&tlsFlags.CACert, "cacert", "", "PEM file of CA certificates to trust in addition to the system roots")
+	c.Flags().StringVar(&tlsFlags.Cert, "cert", "", "Client certificate (PEM) for mutual TLS")
+	c.Flags().StringVar(&tlsFlags.Key, "key", "", "Private key (PEM) for --cert, if not in the same file")
+	c.Flags().BoolVarP(&tlsFlags.Insecure, "insecure", "k", false, "Skip server certificate verification")
+	c.Flags().StringVar(&tlsFlags.MinVersion, "tls-min-version", "", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	c.Flags().StringVar(&tlsFlags.ServerName, "servername", "", "Server name for SNI and certificate verification")
+}
+
+// tlsOptions layers TLS settings: the environment file first, then those
+// stored with a request, then command-line flags.
+func tlsOptions(saved internal.TLSOptions) internal.TLSOptions {
+	return envTLSOptions().Merge(saved).Merge(tlsFlags)
+}
+
+// envTLSOptions reads TLS settings from reserved keys in the environment
+// file. Relative certificate paths are resolved against the file's
+// directory.
+//
+//	{
+//	  "apitester.cacert": "certs/internal-ca.pem",
+//	  "apitester.cert": "certs/client.pem",
+//	  "apitester.key": "certs/client-key.pem",
+//	  "apitester.insecure": "false",
+//	  "apitester.tls_min_version": "1.2",
+//	  "apitester.servername": "api.internal"
+//	}
+func envTLSOptions() internal.TLSOptions {
+	insecure, _ := strconv.ParseBool(Env["apitester.insecure"])
+	return internal.TLSOptions{
+		CACert:     envPath(Env["apitester.cacert"]),
+		Cert:       envPath(Env["apitester.cert"]),
+		Key:        envPath(Env["apitester.key"]),
+		Insecure:   insecure,
+		MinVersion: Env["apitester.tls_min_version"],
+		ServerName: Env["apitester.servername"],
+	}
+}
+
+// envPath resolves a path from the environment file relative to that file.
+func envPath(p string) string {
+	if p == "" || filepath.IsAbs(p) || envFile == "" {
+		return p
+	}
+	return filepath.Join(filepath.Dir(envFile), p)
+}
+
+// addProxyFlag registers --proxy.
+func addProxyFlag(c *cobra.Command) {
+	c.Flags().StringVar(&proxyFlag, "proxy", "", "Proxy URL (http://, https:// or socks5://, optionally with user:pass@); overrides HTTP_PROXY/HTTPS_PROXY")
+}
+
+// proxyOption returns the proxy to use: --proxy, then "apitester.proxy"
+// from the environment file. An empty result falls back to the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables.
+func proxyOption() string {
+	if proxyFlag != "" {
+		return proxyFlag
+	}
+	return Env["apitester.proxy"]
+}
+
+// addRedirectFlags registers the redirect-handling flags.
+func addRedirectFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&followFlag, "follow", true, "Follow redirects")
+	c.Flags().BoolVar(&noFollowFlag, "no-follow", false, "Do not follow redirects; show the 3xx response instead")
+	c.Flags().IntVar(&maxRedirectsFlag, "max-redirects", 10, "Maximum number of redirects to follow (0 to not follow, -1 for no limit)")
+	c.Flags().BoolVar(&keepAuthFlag, "keep-auth", false, "Keep the Authorization header when a redirect goes to another host")
+}
+
+// applyRedirectFlags copies the redirect flags given on the command line
+// into opts. Flags left at their defaults keep what opts already says, such
+// as a saved request's settings or a parsed curl command's --max-redirs.
+func applyRedirectFlags(c *cobra.Command, opts *internal.RequestOptions) {
+	f := c.Flags()
+	if f.Changed("follow") {
+		opts.NoFollow = !followFlag
+	}
+	if f.Changed("no-follow") && noFollowFlag {
+		opts.NoFollow = true
+	}
+	if f.Changed("max-redirects") {
+		if maxRedirectsFlag == 0 {
+			opts.NoFollow = true
+		} else {
+			opts.MaxRedirects = maxRedirectsFlag
+		}
+	}
+	if f.Changed("keep-auth") {
+		opts.KeepAuth = keepAuthFlag
+	}
+}
+
+// addTimeoutFlags registers --timeout and the per-phase timeouts.
+func addTimeoutFlags(c *cobra.Command) {
+	c.Flags().DurationVar(&timeoutFlag, "timeout", 0, "Overall timeout per request, e.g. 30s (default 10s for get/delete/head/options, 15s otherwise)")
+	c.Flags().DurationVar(&timeoutsFlags.Connect, "connect-timeout", 0, "Limit on establishing the TCP connection (default 30s)")
+	c.Flags().DurationVar(&timeoutsFlags.TLSHandshake, "tls-timeout", 0, "Limit on the TLS handshake (default 10s)")
+	c.Flags().DurationVar(&timeoutsFlags.ResponseHeader, "response-header-timeout", 0, "Limit on waiting for response headers once the request is sent (default none)")
+}
+
+// checkTimeoutFlags rejects negative timeouts.
+func checkTimeoutFlags() error {
+	for name, d := range map[string]time.Duration{
+		"--timeout":                 timeoutFlag,
+		"--connect-timeout":         timeoutsFlags.Connect,
+		"--tls-timeout":             timeoutsFlags.TLSHandshake,
+		"--response-header-timeout": timeoutsFlags.ResponseHeader,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// requestTimeout returns --timeout if given, otherwise def.
+func requestTimeout(def time.Duration) time.Duration {
+	if timeoutFlag > 0 {
+		return timeoutFlag
+	}
+	return def
+}
+
+// applyTimeoutFlags overrides the timeouts in opts with any given on the
+// command line.
+func applyTimeoutFlags(opts *internal.RequestOptions) {
+	opts.Timeout = requestTimeout(opts.Timeout)
+	if timeoutsFlags.Connect > 0 {
+		opts.Timeouts.Connect = timeoutsFlags.Connect
+	}
+	if timeoutsFlags.TLSHandshake > 0 {
+		opts.Timeouts.TLSHandshake = timeoutsFlags.TLSHandshake
+	}
+	if timeoutsFlags.ResponseHeader > 0 {
+		opts.Timeouts.ResponseHeader = timeoutsFlags.ResponseHeader
+	}
+}
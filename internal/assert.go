@@ -0,0 +1,383 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Assertion is a single declarative check evaluated against a response.
+//
+// Assertions are written as short expressions, either on the command line
+// via --expect or stored on a SavedRequest:
+//
+//	status == 2xx                       status code, class, range, or list
+//	header Content-Type contains json   header equals/contains/matches/exists
+//	$.user.id exists                    JSONPath against the response body
+//	$.user.name == "alice"
+//	body contains "ok"                  raw body text
+//	latency < 500ms                     total round-trip time
+//	size <= 10KB                        response body size
+type Assertion struct {
+	Target string `json:"target"`
+	Key    string `json:"key,omitempty"`
+	Op     string `json:"op"`
+	Value  string `json:"value,omitempty"`
+}
+
+// AssertionResult is the outcome of evaluating one Assertion.
+type AssertionResult struct {
+	Assertion Assertion
+	Passed    bool
+	Actual    string
+}
+
+// assertionOps lists the operators each assertion target accepts.
+var assertionOps = map[string][]string{
+	"status":  {"==", "!=", "<", "<=", ">", ">="},
+	"header":  {"==", "!=", "contains", "matches", "exists"},
+	"body":    {"==", "!=", "contains", "matches", "exists", "<", "<=", ">", ">="},
+	"latency": {"<", "<=", ">", ">="},
+	"size":    {"==", "!=", "<", "<=", ">", ">="},
+}
+
+// ParseAssertions parses a list of assertion expressions.
+func ParseAssertions(exprs []string) ([]Assertion, error) {
+	assertions := make([]Assertion, 0, len(exprs))
+	for _, e := range exprs {
+		a, err := ParseAssertion(e)
+		if err != nil {
+			return nil, err
+		}
+		assertions = append(assertions, a)
+	}
+	return assertions, nil
+}
+
+// ParseAssertion parses an expression such as "status == 2xx" or
+// "$.id exists" into an Assertion, validating its operator and value.
+func ParseAssertion(expr string) (Assertion, error) {
+	var a Assertion
+	target, rest := nextToken(expr)
+
+	switch {
+	case target == "status" || target == "latency" || target == "size":
+		a.Target = target
+	case target == "header":
+		a.Target = target
+		a.Key, rest = nextToken(rest)
+		if a.Key == "" {
+			return a, fmt.Errorf("invalid assertion %q: missing header name", expr)
+		}
+	case target == "body":
+		a.Target = target
+		if tok, r := nextToken(rest); strings.HasPrefix(tok, "$") {
+			a.Key, rest = tok, r
+		}
+	case strings.HasPrefix(target, "$"):
+		a.Target = "body"
+		a.Key = target
+	default:
+		return a, fmt.Errorf("invalid assertion %q: unknown target %q (want status, header, body, $.path, latency, or size)", expr, target)
+	}
+
+	a.Op, rest = nextToken(rest)
+	if a.Op == "=" {
+		a.Op = "=="
+	}
+	a.Value = strings.TrimSpace(rest)
+
+	if !containsString(assertionOps[a.Target], a.Op) {
+		return a, fmt.Errorf("invalid assertion %q: operator %q not supported for %s (want one of %s)",
+			expr, a.Op, a.Target, strings.Join(assertionOps[a.Target], ", "))
+	}
+	if a.Target == "body" && a.Key == "" && isOrderedOp(a.Op) {
+		return a, fmt.Errorf("invalid assertion %q: %q needs a JSONPath (use size to check the body length)", expr, a.Op)
+	}
+	if a.Op == "exists" {
+		if a.Value != "" {
+			return a, fmt.Errorf("invalid assertion %q: 'exists' takes no value", expr)
+		}
+		if a.Target == "body" && a.Key == "" {
+			return a, fmt.Errorf("invalid assertion %q: 'exists' needs a JSONPath", expr)
+		}
+		return a, nil
+	}
+	if a.Value == "" {
+		return a, fmt.Errorf("invalid assertion %q: missing value", expr)
+	}
+
+	var err error
+	switch {
+	case a.Target == "status":
+		if a.Op == "==" || a.Op == "!=" {
+			_, err = matchStatus(a.Value, 0)
+		} else {
+			_, err = strconv.Atoi(a.Value)
+		}
+	case a.Target == "latency":
+		_, err = time.ParseDuration(a.Value)
+	case a.Target == "size":
+		_, err = parseSize(a.Value)
+	case a.Op == "matches":
+		_, err = regexp.Compile(a.Value)
+	case isOrderedOp(a.Op):
+		_, err = strconv.ParseFloat(a.Value, 64)
+	}
+	if err != nil {
+		return a, fmt.Errorf("invalid assertion %q: %w", expr, err)
+	}
+	return a, nil
+}
+
+// String renders the assertion back into its expression form.
+func (a Assertion) String() string {
+	parts := []string{a.Target}
+	if a.Target == "body" && strings.HasPrefix(a.Key, "$") {
+		parts = []string{a.Key}
+	} else if a.Key != "" {
+		parts = append(parts, a.Key)
+	}
+	parts = append(parts, a.Op)
+	if a.Value != "" {
+		parts = append(parts, a.Value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// EvaluateAssertions checks every assertion against a response.
+func EvaluateAssertions(assertions []Assertion, resp *http.Response, body []byte, duration time.Duration) []AssertionResult {
+	results := make([]AssertionResult, 0, len(assertions))
+	for _, a := range assertions {
+		results = append(results, a.Evaluate(resp, body, duration))
+	}
+	return results
+}
+
+// Evaluate checks a single assertion against a response.
+func (a Assertion) Evaluate(resp *http.Response, body []byte, duration time.Duration) AssertionResult {
+	r := AssertionResult{Assertion: a}
+
+	switch a.Target {
+	case "status":
+		r.Actual = strconv.Itoa(resp.StatusCode)
+		if a.Op == "==" || a.Op == "!=" {
+			ok, _ := matchStatus(a.Value, resp.StatusCode)
+			r.Passed = ok == (a.Op == "==")
+		} else {
+			want, _ := strconv.Atoi(a.Value)
+			r.Passed = compareOrdered(a.Op, float64(resp.StatusCode), float64(want))
+		}
+
+	case "header":
+		values := resp.Header.Values(a.Key)
+		if len(values) == 0 {
+			r.Actual = "<missing>"
+			r.Passed = a.Op == "!="
+			break
+		}
+		r.Actual = strings.Join(values, ", ")
+		r.Passed = compareText(a.Op, r.Actual, a.Value)
+
+	case "body":
+		if a.Key == "" {
+			r.Actual = fmt.Sprintf("%d bytes", len(body))
+			r.Passed = compareText(a.Op, string(body), a.Value)
+			break
+		}
+		v, found, err := EvalJSONPath(body, a.Key)
+		if err != nil {
+			r.Actual = err.Error()
+			break
+		}
+		if !found {
+			r.Actual = "<missing>"
+			r.Passed = a.Op == "!="
+			break
+		}
+		r.Actual = jsonValueString(v)
+		switch {
+		case a.Op == "exists":
+			r.Passed = true
+		case a.Op == "==" || a.Op == "!=":
+			r.Passed = jsonValueEquals(v, a.Value) == (a.Op == "==")
+		case isOrderedOp(a.Op):
+			n, ok := v.(float64)
+			want, _ := strconv.ParseFloat(a.Value, 64)
+			r.Passed = ok && compareOrdered(a.Op, n, want)
+		default:
+			r.Passed = compareText(a.Op, r.Actual, a.Value)
+		}
+
+	case "latency":
+		r.Actual = duration.Round(time.Millisecond).String()
+		want, _ := time.ParseDuration(a.Value)
+		r.Passed = compareOrdered(a.Op, float64(duration), float64(want))
+
+	case "size":
+		r.Actual = fmt.Sprintf("%d bytes", len(body))
+		want, _ := parseSize(a.Value)
+		if a.Op == "==" || a.Op == "!=" {
+			r.Passed = (int64(len(body)) == want) == (a.Op == "==")
+		} else {
+			r.Passed = compareOrdered(a.Op, float64(len(body)), float64(want))
+		}
+	}
+	return r
+}
+
+// PrintAssertionResults prints one line per assertion result, indented by
+// the given prefix, and returns the number of failures.
+func PrintAssertionResults(results []AssertionResult, indent string) int {
+	failed := 0
+	for _, r := range results {
+		if r.Passed {
+			fmt.Printf("%s✅ %s\n", indent, r.Assertion)
+			continue
+		}
+		failed++
+		fmt.Printf("%s❌ %s  (actual: %s)\n", indent, r.Assertion, r.Actual)
+	}
+	return failed
+}
+
+// matchStatus reports whether code satisfies a status expression: an exact
+// code ("200"), a class ("2xx"), a range ("200-299"), or a comma-separated
+// list of any of these. It also validates the expression.
+func matchStatus(expr string, code int) (bool, error) {
+	matched := false
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case len(part) == 3 && strings.EqualFold(part[1:], "xx"):
+			class, err := strconv.Atoi(part[:1])
+			if err != nil {
+				return false, fmt.Errorf("bad status class %q", part)
+			}
+			if code/100 == class {
+				matched = true
+			}
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			lo, err1 := strconv.Atoi(strings.TrimSpace(bounds[0]))
+			hi, err2 := strconv.Atoi(strings.TrimSpace(bounds[1]))
+			if err1 != nil || err2 != nil || lo > hi {
+				return false, fmt.Errorf("bad status range %q", part)
+			}
+			if code >= lo && code <= hi {
+				matched = true
+			}
+		default:
+			want, err := strconv.Atoi(part)
+			if err != nil {
+				return false, fmt.Errorf("bad status code %q", part)
+			}
+			if code == want {
+				matched = true
+			}
+		}
+	}
+	return matched, nil
+}
+
+// jsonValueEquals compares a decoded JSON value against an expected literal.
+// The literal is decoded as JSON when possible (so 42, true, null and
+// "quoted" work as expected); otherwise it is compared as plain text.
+func jsonValueEquals(actual interface{}, expected string) bool {
+	var want interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err == nil {
+		return reflect.DeepEqual(actual, want)
+	}
+	return jsonValueString(actual) == expected
+}
+
+// compareText applies a textual operator to an actual and expected value.
+func compareText(op, actual, expected string) bool {
+	switch op {
+	case "==":
+		return actual == unquote(expected)
+	case "!=":
+		return actual != unquote(expected)
+	case "contains":
+		return strings.Contains(actual, unquote(expected))
+	case "matches":
+		re, err := regexp.Compile(expected)
+		return err == nil && re.MatchString(actual)
+	case "exists":
+		return true
+	}
+	return false
+}
+
+// compareOrdered applies a numeric comparison operator.
+func compareOrdered(op string, actual, expected float64) bool {
+	switch op {
+	case "<":
+		return actual < expected
+	case "<=":
+		return actual <= expected
+	case ">":
+		return actual > expected
+	case ">=":
+		return actual >= expected
+	case "==":
+		return actual == expected
+	case "!=":
+		return actual != expected
+	}
+	return false
+}
+
+func isOrderedOp(op string) bool {
+	return op == "<" || op == "<=" || op == ">" || op == ">="
+}
+
+// parseSize parses a byte size such as "512", "10KB" or "2MB".
+func parseSize(s string) (int64, error) {
+	u := strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	switch {
+	case strings.HasSuffix(u, "KB"):
+		mult, u = 1024, strings.TrimSuffix(u, "KB")
+	case strings.HasSuffix(u, "MB"):
+		mult, u = 1024*1024, strings.TrimSuffix(u, "MB")
+	case strings.HasSuffix(u, "B"):
+		u = strings.TrimSuffix(u, "B")
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(u), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("bad size %q", s)
+	}
+	return n * mult, nil
+}
+
+// nextToken splits off the first whitespace-delimited token of s.
+func nextToken(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// unquote strips one pair of matching surrounding quotes, if present.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseAssertion(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "status == 2xx"},
+		{expr: "header Content-Type contains json"},
+		{expr: "body contains \"ok\""},
+		{expr: "body matches ^ok$"},
+		{expr: "$.count > 3"},
+		{expr: "body $.count <= 3"},
+		{expr: "size < 10KB"},
+		{expr: "body < 100", wantErr: true},
+		{expr: "body >= 1", wantErr: true},
+		{expr: "body exists", wantErr: true},
+		{expr: "$.count > many", wantErr: true},
+		{expr: "latency == 5ms", wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := ParseAssertion(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAssertion(%q) error = %v, want error %v", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEvaluateOrderedJSONPath(t *testing.T) {
+	resp := &http.Response{StatusCode: 200, Header: http.Header{}}
+	body := []byte(`{"count": 5}`)
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"$.count > 3", true},
+		{"$.count < 3", false},
+		{"body $.count >= 5", true},
+	}
+	for _, tt := range tests {
+		a, err := ParseAssertion(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseAssertion(%q): %v", tt.expr, err)
+		}
+		if got := a.Evaluate(resp, body, time.Millisecond); got.Passed != tt.want {
+			t.Errorf("%q passed = %v, want %v (actual %s)", tt.expr, got.Passed, tt.want, got.Actual)
+		}
+	}
+}
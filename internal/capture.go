@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Capture extracts a value from a response and stores it in the environment
+// so later requests can reference it as {{Name}}.
+//
+// Captures are written as name=source expressions:
+//
+//	token=$.data.token          JSONPath against the response body
+//	request_id=header:X-Request-Id
+//	session=cookie:SESSIONID
+type Capture struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Path   string `json:"path"`
+}
+
+// CaptureResult is the outcome of applying one Capture.
+type CaptureResult struct {
+	Capture Capture
+	Err     error
+}
+
+// ParseCaptures parses a list of capture expressions.
+func ParseCaptures(exprs []string) ([]Capture, error) {
+	captures := make([]Capture, 0, len(exprs))
+	for _, e := range exprs {
+		c, err := ParseCapture(e)
+		if err != nil {
+			return nil, err
+		}
+		captures = append(captures, c)
+	}
+	return captures, nil
+}
+
+// ParseCapture parses an expression such as "token=$.token" or
+// "sid=cookie:session" into a Capture.
+func ParseCapture(expr string) (Capture, error) {
+	name, src, ok := strings.Cut(expr, "=")
+	name, src = strings.TrimSpace(name), strings.TrimSpace(src)
+	if !ok || name == "" || src == "" {
+		return Capture{}, fmt.Errorf("invalid capture %q: want name=$.path, name=header:Name, or name=cookie:name", expr)
+	}
+
+	c := Capture{Name: name}
+	switch {
+	case strings.HasPrefix(src, "$"):
+		c.Source, c.Path = "body", src
+	case strings.HasPrefix(src, "body:"):
+		c.Source, c.Path = "body", strings.TrimPrefix(src, "body:")
+	case strings.HasPrefix(src, "header:"):
+		c.Source, c.Path = "header", strings.TrimPrefix(src, "header:")
+	case strings.HasPrefix(src, "cookie:"):
+		c.Source, c.Path = "cookie", strings.TrimPrefix(src, "cookie:")
+	default:
+		return Capture{}, fmt.Errorf("invalid capture %q: unknown source %q", expr, src)
+	}
+
+	if c.Path == "" {
+		return Capture{}, fmt.Errorf("invalid capture %q: missing %s name", expr, c.Source)
+	}
+	if c.Source == "body" {
+		if _, err := parseJSONPath(c.Path); err != nil {
+			return Capture{}, fmt.Errorf("invalid capture %q: %w", expr, err)
+		}
+	}
+	return c, nil
+}
+
+// String renders the capture back into its expression form.
+func (c Capture) String() string {
+	if c.Source == "body" {
+		return c.Name + "=" + c.Path
+	}
+	return c.Name + "=" + c.Source + ":" + c.Path
+}
+
+// Extract pulls the captured value out of a response.
+func (c Capture) Extract(resp *http.Response, body []byte) (string, error) {
+	switch c.Source {
+	case "body":
+		v, found, err := EvalJSONPath(body, c.Path)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return "", fmt.Errorf("%s not found in response body", c.Path)
+		}
+		return jsonValueString(v), nil
+	case "header":
+		if v := resp.Header.Get(c.Path); v != "" {
+			return v, nil
+		}
+		return "", fmt.Errorf("response header %q not present", c.Path)
+	case "cookie":
+		for _, ck := range resp.Cookies() {
+			if ck.Name == c.Path {
+				return ck.Value, nil
+			}
+		}
+		return "", fmt.Errorf("cookie %q not set by response", c.Path)
+	}
+	return "", fmt.Errorf("unknown capture source %q", c.Source)
+}
+
+// ApplyCaptures extracts every capture from a response and writes the values
+// into env. Captures that fail leave the environment untouched.
+func ApplyCaptures(env Env, captures []Capture, resp *http.Response, body []byte) []CaptureResult {
+	results := make([]CaptureResult, 0, len(captures))
+	for _, c := range captures {
+		v, err := c.Extract(resp, body)
+		if err == nil {
+			env[c.Name] = v
+		}
+		results = append(results, CaptureResult{Capture: c, Err: err})
+	}
+	return results
+}
+
+// PrintCaptureResults prints one line per capture, indented by the given
+// prefix, and returns the number of failures. Captured values are not
+// printed since they are frequently credentials.
+func PrintCaptureResults(results []CaptureResult, indent string) int {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("%s❌ capture {{%s}}: %v\n", indent, r.Capture.Name, r.Err)
+			continue
+		}
+		fmt.Printf("%s📌 captured {{%s}} from %s\n", indent, r.Capture.Name, strings.TrimPrefix(r.Capture.String(), r.Capture.Name+"="))
+	}
+	return failed
+}
@@ -4,19 +4,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"time"
 )
 
 // SavedRequest is a serialized request that can be stored and replayed.
 type SavedRequest struct {
-	Name    string            `json:"name"`
-	Method  string            `json:"method"`
-	URL     string            `json:"url"`
-	Headers map[string]string `json:"headers,omitempty"`
-	Body    string            `json:"body,omitempty"`
-	Auth    string            `json:"auth,omitempty"`
-	Timeout time.Duration     `json:"timeout_ns,omitempty"`
+	Name     string            `json:"name"`
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	Query    map[string]string `json:"query,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	Form     []FormPart        `json:"form,omitempty"`
+	Auth     string            `json:"auth,omitempty"`
+	Timeout  time.Duration     `json:"timeout_ns,omitempty"`
+	Timeouts Timeouts          `json:"timeouts,omitzero"` // per-phase limits, e.g. from curl --connect-timeout
+	Tags     []string          `json:"tags,omitempty"`
+	TLS      TLSOptions        `json:"tls,omitzero"`
+	Retry    RetryPolicy       `json:"retry,omitzero"`
+
+	NoFollow     bool `json:"no_follow,omitempty"`     // return 3xx responses instead of following them
+	MaxRedirects int  `json:"max_redirects,omitempty"` // 0 means 10, negative means no limit
+	KeepAuth     bool `json:"keep_auth,omitempty"`     // keep Authorization when a redirect changes host
+
+	Assertions []Assertion `json:"assertions,omitempty"`
+	Captures   []Capture   `json:"captures,omitempty"`
 }
 
 // Collection is the top-level JSON structure for the collections file.
@@ -103,6 +117,35 @@ func SaveRequest(req SavedRequest) error {
 	return nil
 }
 
+// SaveRequests saves (or overwrites) several requests in one write and
+// reports how many were added and how many replaced an existing entry.
+func SaveRequests(reqs []SavedRequest) (added, updated int, err error) {
+	col, err := loadCollection()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	index := make(map[string]int, len(col.Requests))
+	for i, r := range col.Requests {
+		index[r.Name] = i
+	}
+	for _, req := range reqs {
+		if i, ok := index[req.Name]; ok {
+			col.Requests[i] = req
+			updated++
+			continue
+		}
+		index[req.Name] = len(col.Requests)
+		col.Requests = append(col.Requests, req)
+		added++
+	}
+
+	if err := saveCollection(col); err != nil {
+		return 0, 0, err
+	}
+	return added, updated, nil
+}
+
 // GetRequest retrieves a saved request by name.
 func GetRequest(name string) (SavedRequest, error) {
 	col, err := loadCollection()
@@ -151,3 +194,43 @@ func ListRequests() ([]SavedRequest, error) {
 	}
 	return col.Requests, nil
 }
+
+// MatchRequests returns the saved requests, in collection order, whose name
+// matches the glob pattern (path.Match syntax) and which carry at least one of
+// the given tags. An empty pattern or empty tag list matches everything.
+func MatchRequests(pattern string, tags []string) ([]SavedRequest, error) {
+	col, err := loadCollection()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []SavedRequest
+	for _, r := range col.Requests {
+		if pattern != "" {
+			ok, err := path.Match(pattern, r.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid name pattern %q: %w", pattern, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		if len(tags) > 0 && !r.HasAnyTag(tags) {
+			continue
+		}
+		matched = append(matched, r)
+	}
+	return matched, nil
+}
+
+// HasAnyTag reports whether the request carries at least one of the tags.
+func (r SavedRequest) HasAnyTag(tags []string) bool {
+	for _, want := range tags {
+		for _, have := range r.Tags {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
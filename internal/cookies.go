@@ -0,0 +1,442 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// StoredCookie is a cookie as kept in the cookie jar file.
+type StoredCookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires,omitzero"` // zero for session cookies
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"http_only,omitempty"`
+	HostOnly bool      `json:"host_only,omitempty"` // sent to Domain only, not its subdomains
+}
+
+// expired reports whether the cookie should no longer be sent.
+func (c *StoredCookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+// CookieJar is an http.CookieJar that can be saved to and loaded from disk,
+// in either Netscape (curl/wget) or JSON format. Session cookies are kept
+// too, so a login in one invocation carries over to the next. A jar with no
+// path lives in memory only.
+type CookieJar struct {
+	mu      sync.Mutex
+	path    string
+	cookies []*StoredCookie
+	dirty   bool
+}
+
+// LoadCookieJar reads a cookie jar file, returning an empty jar if it does
+// not exist yet. The format is detected from the file's contents. An empty
+// path gives a jar kept in memory only.
+func LoadCookieJar(path string) (*CookieJar, error) {
+	jar := &CookieJar{path: path}
+	if path == "" {
+		return jar, nil
+	}
+	if err := CheckLocalFileAccess("--cookie-jar " + path); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return jar, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read cookie jar: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+	case trimmed[0] == '[':
+		if err := json.Unmarshal(trimmed, &jar.cookies); err != nil {
+			return nil, fmt.Errorf("invalid cookie jar %q: %w", path, err)
+		}
+	default:
+		if jar.cookies, err = parseNetscapeCookies(data); err != nil {
+			return nil, fmt.Errorf("invalid cookie jar %q: %w", path, err)
+		}
+	}
+	return jar, nil
+}
+
+// Path returns the file the jar is saved to.
+func (j *CookieJar) Path() string {
+	return j.path
+}
+
+// Save writes the jar back to disk if it changed. Files ending in .json are
+// written as JSON, anything else in Netscape format.
+func (j *CookieJar) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if !j.dirty || j.path == "" {
+		return nil
+	}
+	j.removeExpired(time.Now())
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(j.path), ".json") {
+		var err error
+		if data, err = json.MarshalIndent(j.cookies, "", "  "); err != nil {
+			return fmt.Errorf("could not serialize cookies: %w", err)
+		}
+		data = append(data, '\n')
+	} else {
+		data = formatNetscapeCookies(j.cookies)
+	}
+
+	if err := os.WriteFile(j.path, data, 0600); err != nil {
+		return fmt.Errorf("could not write cookie jar: %w", err)
+	}
+	j.dirty = false
+	return nil
+}
+
+// All returns every unexpired cookie, sorted by domain, path and name.
+func (j *CookieJar) All() []StoredCookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	var all []StoredCookie
+	for _, c := range j.cookies {
+		if !c.expired(now) {
+			all = append(all, *c)
+		}
+	}
+	sort.Slice(all, func(a, b int) bool {
+		if all[a].Domain != all[b].Domain {
+			return all[a].Domain < all[b].Domain
+		}
+		if all[a].Path != all[b].Path {
+			return all[a].Path < all[b].Path
+		}
+		return all[a].Name < all[b].Name
+	})
+	return all
+}
+
+// Clear removes every cookie for domain and its subdomains, or all cookies
+// when domain is empty, and returns how many were removed.
+func (j *CookieJar) Clear(domain string) int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	kept := j.cookies[:0]
+	for _, c := range j.cookies {
+		if domain != "" && c.Domain != domain && !strings.HasSuffix(c.Domain, "."+domain) {
+			kept = append(kept, c)
+		}
+	}
+	removed := len(j.cookies) - len(kept)
+	j.cookies = kept
+	if removed > 0 {
+		j.dirty = true
+	}
+	return removed
+}
+
+// SetCookies implements http.CookieJar following the storage rules of
+// RFC 6265. A Domain attribute naming a public suffix such as "com" or
+// "co.uk" is refused, unless it is the request host itself, in which case
+// the cookie is kept for that host only.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	host := canonicalHost(u)
+	now := time.Now()
+	for _, hc := range cookies {
+		c := &StoredCookie{
+			Name:     hc.Name,
+			Value:    hc.Value,
+			Path:     hc.Path,
+			Secure:   hc.Secure,
+			HttpOnly: hc.HttpOnly,
+		}
+
+		if hc.Domain == "" {
+			c.Domain, c.HostOnly = host, true
+		} else {
+			d := strings.TrimPrefix(strings.ToLower(hc.Domain), ".")
+			switch {
+			case isPublicSuffix(d):
+				if d != host {
+					continue // a site may not set cookies for a whole registry
+				}
+				c.Domain, c.HostOnly = host, true
+			case !domainMatch(host, d):
+				continue // a site may not set cookies for another domain
+			default:
+				c.Domain = d
+			}
+		}
+		if c.Path == "" || c.Path[0] != '/' {
+			c.Path = defaultCookiePath(u.Path)
+		}
+
+		switch {
+		case hc.MaxAge < 0:
+			c.Expires = now.Add(-time.Second)
+		case hc.MaxAge > 0:
+			c.Expires = now.Add(time.Duration(hc.MaxAge) * time.Second)
+		case !hc.Expires.IsZero():
+			c.Expires = hc.Expires
+		}
+
+		j.replace(c)
+	}
+	j.removeExpired(now)
+}
+
+// Cookies implements http.CookieJar.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	host := canonicalHost(u)
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+	now := time.Now()
+
+	var matched []*StoredCookie
+	for _, c := range j.cookies {
+		if c.expired(now) || (c.Secure && !secure) || !pathMatch(path, c.Path) {
+			continue
+		}
+		if c.HostOnly && host != c.Domain || !c.HostOnly && !domainMatch(host, c.Domain) {
+			continue
+		}
+		matched = append(matched, c)
+	}
+	// Longer paths first, as RFC 6265 recommends.
+	sort.SliceStable(matched, func(a, b int) bool { return len(matched[a].Path) > len(matched[b].Path) })
+
+	out := make([]*http.Cookie, 0, len(matched))
+	for _, c := range matched {
+		out = append(out, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return out
+}
+
+// CookieHeader returns the Cookie header value the jar would send to
+// rawURL, or "" if there are none.
+func (j *CookieJar) CookieHeader(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	var parts []string
+	for _, c := range j.Cookies(u) {
+		parts = append(parts, c.Name+"="+c.Value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// replace stores c, overwriting any cookie with the same name, domain and
+// path.
+func (j *CookieJar) replace(c *StoredCookie) {
+	j.dirty = true
+	for i, old := range j.cookies {
+		if old.Name == c.Name && old.Domain == c.Domain && old.Path == c.Path {
+			j.cookies[i] = c
+			return
+		}
+	}
+	j.cookies = append(j.cookies, c)
+}
+
+func (j *CookieJar) removeExpired(now time.Time) {
+	kept := j.cookies[:0]
+	for _, c := range j.cookies {
+		if !c.expired(now) {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) != len(j.cookies) {
+		j.dirty = true
+	}
+	j.cookies = kept
+}
+
+// canonicalHost returns the lower-cased host of u without its port.
+func canonicalHost(u *url.URL) string {
+	host := u.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
+
+// domainMatch reports whether host is domain or a subdomain of it. IP
+// addresses only match themselves.
+func domainMatch(host, domain string) bool {
+	if host == domain {
+		return true
+	}
+	return net.ParseIP(host) == nil && strings.HasSuffix(host, "."+domain)
+}
+
+// isPublicSuffix reports whether domain is a single label or a suffix
+// under which anyone can register names, like "com" or "github.io".
+func isPublicSuffix(domain string) bool {
+	if net.ParseIP(domain) != nil {
+		return false
+	}
+	suffix, _ := publicsuffix.PublicSuffix(domain)
+	return !strings.Contains(domain, ".") || suffix == domain
+}
+
+// pathMatch implements the RFC 6265 path-match algorithm.
+func pathMatch(reqPath, cookiePath string) bool {
+	if reqPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(reqPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || reqPath[len(cookiePath)] == '/'
+}
+
+// defaultCookiePath is the directory of the request path, per RFC 6265.
+func defaultCookiePath(p string) string {
+	if p == "" || p[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(p, "/")
+	if i == 0 {
+		return "/"
+	}
+	return p[:i]
+}
+
+// parseNetscapeCookies reads the tab-separated format used by curl -c and
+// browser extensions:
+//
+//	domain  include-subdomains  path  secure  expires  name  value
+//
+// Lines starting with "#HttpOnly_" hold HttpOnly cookies; other lines
+// starting with "#" are comments.
+func parseNetscapeCookies(data []byte) ([]*StoredCookie, error) {
+	var cookies []*StoredCookie
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		if strings.HasPrefix(text, "#HttpOnly_") {
+			text = strings.TrimPrefix(text, "#HttpOnly_")
+			httpOnly = true
+		}
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) == 6 {
+			fields = append(fields, "") // empty value
+		}
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab-separated fields, got %d", line, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", line, fields[4])
+		}
+
+		c := &StoredCookie{
+			Domain:   strings.TrimPrefix(strings.ToLower(fields[0]), "."),
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		if expires > 0 {
+			c.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, c)
+	}
+	return cookies, scanner.Err()
+}
+
+// formatNetscapeCookies writes cookies in the format read by
+// parseNetscapeCookies. Session cookies get an expiry of 0.
+func formatNetscapeCookies(cookies []*StoredCookie) []byte {
+	var b bytes.Buffer
+	b.WriteString("# Netscape HTTP Cookie File\n# Written by apitester. Edit at your own risk.\n\n")
+
+	upper := func(v bool) string {
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	for _, c := range cookies {
+		domain := c.Domain
+		if !c.HostOnly {
+			domain = "." + domain
+		}
+		if c.HttpOnly {
+			domain = "#HttpOnly_" + domain
+		}
+		var expires int64
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Unix()
+		}
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, upper(!c.HostOnly), c.Path, upper(c.Secure), expires, c.Name, c.Value)
+	}
+	return b.Bytes()
+}
+
+// PrintCookies prints the cookies as a table.
+func PrintCookies(cookies []StoredCookie) {
+	fmt.Printf("%-28s  %-12s  %-20s  %-30s  %s\n", "DOMAIN", "PATH", "NAME", "VALUE", "EXPIRES")
+	fmt.Println(strings.Repeat("─", 110))
+	for _, c := range cookies {
+		domain := c.Domain
+		if !c.HostOnly {
+			domain = "." + domain
+		}
+		expires := "session"
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Local().Format("2006-01-02 15:04")
+		}
+		value := c.Value
+		if len(value) > 30 {
+			value = value[:27] + "..."
+		}
+		fmt.Printf("%-28s  %-12s  %-20s  %-30s  %s\n", domain, c.Path, c.Name, value, expires)
+	}
+}
@@ -0,0 +1,592 @@
+package internal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// curlCommand renders a request as a multi-line curl invocation. Every
+// argument value is passed through quote, which decides how the value is
+// protected from (or exposed to) the shell.
+func curlCommand(opts RequestOptions, quote func(string) string) string {
+	args := []string{"curl -sS"}
+	if !opts.NoFollow {
+		args[0] += " -L"
+		if opts.KeepAuth {
+			args[0] += " --location-trusted"
+		}
+		if opts.MaxRedirects != 0 && opts.MaxRedirects != 10 {
+			args[0] += fmt.Sprintf(" --max-redirs %d", opts.MaxRedirects)
+		}
+	}
+	if opts.Method != "" && opts.Method != "GET" {
+		args[0] += " -X " + opts.Method
+	}
+	args[0] += " " + quote(opts.URL)
+
+	if opts.Body != "" && !hasHeader(opts.Headers, "Content-Type") {
+		args = append(args, "-H "+quote("Content-Type: application/json"))
+	}
+	for _, k := range sortedKeys(opts.Headers) {
+		args = append(args, "-H "+quote(k+": "+opts.Headers[k]))
+	}
+	if creds, ok := plainBasicCredentials(opts.Auth); ok {
+		args = append(args, "-u "+quote(creds))
+	} else if opts.Auth != "" {
+		args = append(args, "-H "+quote("Authorization: "+AuthorizationValue(opts.Auth)))
+	}
+	if opts.Jar != nil {
+		if cookies := opts.Jar.CookieHeader(opts.URL); cookies != "" {
+			args = append(args, "-b "+quote(cookies))
+		}
+	}
+	for _, p := range opts.Form {
+		args = append(args, "-F "+quote(p.String()))
+	}
+	if opts.Body != "" && len(opts.Form) == 0 {
+		args = append(args, "--data-raw "+quote(opts.Body))
+	}
+	if opts.Timeout > 0 {
+		args = append(args, fmt.Sprintf("--max-time %g", opts.Timeout.Seconds()))
+	}
+	if opts.Timeouts.Connect > 0 {
+		args = append(args, fmt.Sprintf("--connect-timeout %g", opts.Timeouts.Connect.Seconds()))
+	}
+	if opts.Retry.Max > 0 {
+		args = append(args, fmt.Sprintf("--retry %d", opts.Retry.Max))
+	}
+	args = append(args, curlTLSArgs(opts.TLS, quote)...)
+	if opts.Proxy != "" {
+		args = append(args, "--proxy "+quote(opts.Proxy))
+	}
+	return strings.Join(args, " \\\n  ")
+}
+
+// curlTLSArgs renders TLS options as curl flags. curl has no direct
+// equivalent of a server name override, so it is left out.
+func curlTLSArgs(o TLSOptions, quote func(string) string) []string {
+	var args []string
+	if o.Insecure {
+		args = append(args, "--insecure")
+	}
+	if o.CACert != "" {
+		args = append(args, "--cacert "+quote(o.CACert))
+	}
+	if o.Cert != "" {
+		args = append(args, "--cert "+quote(o.Cert))
+	}
+	if o.Key != "" {
+		args = append(args, "--key "+quote(o.Key))
+	}
+	if o.MinVersion != "" {
+		args = append(args, "--tlsv"+strings.TrimPrefix(strings.ToLower(o.MinVersion), "tls"))
+	}
+	return args
+}
+
+// CurlCommand renders fully interpolated request options as a curl command
+// that can be pasted into a POSIX shell.
+func CurlCommand(opts RequestOptions) string {
+	return curlCommand(opts, shellQuote)
+}
+
+// shellSafe matches strings that need no quoting in a POSIX shell.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote single-quotes s for a POSIX shell when it contains anything
+// the shell would interpret.
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellVarUnsafe matches characters not allowed in shell variable names.
+var shellVarUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// shellVarName converts a {{variable}} name into a valid shell identifier.
+func shellVarName(name string) string {
+	n := shellVarUnsafe.ReplaceAllString(strings.TrimSpace(name), "_")
+	if n == "" || (n[0] >= '0' && n[0] <= '9') {
+		n = "_" + n
+	}
+	return n
+}
+
+// shellQuoteVars double-quotes s for a POSIX shell, turning {{variable}}
+// placeholders into ${variable} expansions and escaping everything else.
+func shellQuoteVars(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	last := 0
+	escape := func(part string) {
+		for _, r := range part {
+			if r == '"' || r == '\\' || r == '$' || r == '`' {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+	}
+	for _, loc := range varPattern.FindAllStringSubmatchIndex(s, -1) {
+		escape(s[last:loc[0]])
+		b.WriteString("${" + shellVarName(s[loc[2]:loc[3]]) + "}")
+		last = loc[1]
+	}
+	escape(s[last:])
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ParseCurl converts a curl command line, as copied from browser devtools or
+// documentation, into RequestOptions. Flags that cannot be represented are
+// reported as warnings rather than errors.
+func ParseCurl(cmdline string) (RequestOptions, []string, error) {
+	words, err := splitShellWords(cmdline)
+	if err != nil {
+		return RequestOptions{}, nil, err
+	}
+	if len(words) > 0 && (words[0] == "curl" || strings.HasSuffix(words[0], "/curl")) {
+		words = words[1:]
+	}
+
+	p := curlParser{opts: RequestOptions{Headers: map[string]string{}}}
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if !strings.HasPrefix(w, "-") || w == "-" {
+			p.setURL(w)
+			continue
+		}
+
+		name, value, hasValue := w, "", false
+		if !strings.HasPrefix(w, "--") && len(w) > 2 {
+			// Short options grouped into one word (-sSL). The first one that
+			// takes a value ends the group and gets the rest of the word
+			// (-XPOST, -sXPOST), or the next word if nothing follows (-sX POST).
+			group := w[1:]
+			for len(group) > 1 && !curlShortWithValue[group[0]] {
+				if err := p.flag("-"+group[:1], ""); err != nil {
+					return RequestOptions{}, nil, err
+				}
+				group = group[1:]
+			}
+			name = "-" + group[:1]
+			if rest := group[1:]; rest != "" {
+				value, hasValue = rest, true
+			}
+		}
+
+		if curlFlagTakesValue(name) && !hasValue {
+			if i+1 >= len(words) {
+				return RequestOptions{}, nil, fmt.Errorf("curl option %s requires a value", name)
+			}
+			i++
+			value = words[i]
+		}
+		if err := p.flag(name, value); err != nil {
+			return RequestOptions{}, nil, err
+		}
+	}
+	return p.finish()
+}
+
+// curlShortWithValue lists the short curl options that take an argument.
+var curlShortWithValue = map[byte]bool{
+	'X': true, 'H': true, 'd': true, 'u': true, 'F': true, 'b': true, 'c': true,
+	'A': true, 'e': true, 'm': true, 'o': true, 'x': true, 'E': true, 'T': true, 'w': true, 'r': true,
+	'U': true,
+}
+
+// curlLongWithValue lists the long curl options that take an argument.
+var curlLongWithValue = map[string]bool{
+	"--request": true, "--header": true, "--data": true, "--data-raw": true, "--data-binary": true,
+	"--data-ascii": true, "--data-urlencode": true, "--json": true, "--user": true, "--form": true,
+	"--form-string": true, "--cookie": true, "--cookie-jar": true, "--url": true, "--user-agent": true,
+	"--referer": true, "--max-time": true, "--connect-timeout": true, "--output": true, "--proxy": true,
+	"--cacert": true, "--cert": true, "--key": true, "--retry": true, "--max-redirs": true,
+	"--upload-file": true, "--retry-delay": true, "--write-out": true, "--range": true, "--resolve": true, "--proxy-user": true,
+}
+
+func curlFlagTakesValue(name string) bool {
+	if strings.HasPrefix(name, "--") {
+		return curlLongWithValue[name]
+	}
+	return len(name) == 2 && curlShortWithValue[name[1]]
+}
+
+// curlParser accumulates state while walking curl arguments.
+type curlParser struct {
+	opts      RequestOptions
+	data      []string
+	method    string
+	getMode   bool
+	location  bool // -L given; curl doesn't follow redirects without it
+	proxyUser string
+	warnings  []string
+}
+
+func (p *curlParser) warnf(format string, args ...interface{}) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+}
+
+func (p *curlParser) setURL(u string) {
+	if p.opts.URL != "" {
+		p.warnf("ignoring extra URL %q; only one request is supported", u)
+		return
+	}
+	p.opts.URL = u
+}
+
+// flag applies a single curl option.
+func (p *curlParser) flag(name, value string) error {
+	switch name {
+	case "-X", "--request":
+		p.method = strings.ToUpper(value)
+	case "-H", "--header":
+		k, v, ok := strings.Cut(value, ":")
+		if !ok {
+			p.warnf("ignoring malformed header %q", value)
+			return nil
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if strings.EqualFold(k, "Accept-Encoding") {
+			p.warnf("dropped %s header; compression is negotiated automatically", k)
+			return nil
+		}
+		p.opts.Headers[k] = v
+	case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw":
+		if strings.HasPrefix(value, "@") && name != "--data-raw" {
+			if err := CheckLocalFileAccess("curl " + name + " " + value); err != nil {
+				return err
+			}
+			data, err := os.ReadFile(strings.TrimPrefix(value, "@"))
+			if err != nil {
+				return fmt.Errorf("curl %s: %w", name, err)
+			}
+			value = string(data)
+			if name != "--data-binary" {
+				value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+			}
+		}
+		p.data = append(p.data, value)
+	case "--data-urlencode":
+		encoded, err := curlURLEncode(value)
+		if err != nil {
+			return err
+		}
+		p.data = append(p.data, encoded)
+	case "--json":
+		p.data = append(p.data, value)
+		if !hasHeader(p.opts.Headers, "Content-Type") {
+			p.opts.Headers["Content-Type"] = "application/json"
+		}
+		if !hasHeader(p.opts.Headers, "Accept") {
+			p.opts.Headers["Accept"] = "application/json"
+		}
+	case "-u", "--user":
+		if !strings.Contains(value, ":") {
+			p.warnf("-u %q has no password; curl would prompt for one", value)
+		}
+		if strings.Contains(value, "{{") {
+			p.opts.Auth = "Basic " + value // encoded once the variables are filled in
+		} else {
+			p.opts.Auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(value))
+		}
+	case "-b", "--cookie":
+		if !strings.Contains(value, "=") {
+			p.warnf("cookie file %q is not supported; cookies skipped", value)
+			return nil
+		}
+		if existing := p.opts.Headers["Cookie"]; existing != "" {
+			value = existing + "; " + value
+		}
+		p.opts.Headers["Cookie"] = value
+	case "-A", "--user-agent":
+		p.opts.Headers["User-Agent"] = value
+	case "-e", "--referer":
+		p.opts.Headers["Referer"] = value
+	case "--url":
+		p.setURL(value)
+	case "-m", "--max-time":
+		secs, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("curl %s: invalid number %q", name, value)
+		}
+		p.opts.Timeout = time.Duration(secs * float64(time.Second))
+	case "--connect-timeout":
+		secs, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("curl %s: invalid number %q", name, value)
+		}
+		p.opts.Timeouts.Connect = time.Duration(secs * float64(time.Second))
+	case "-G", "--get":
+		p.getMode = true
+	case "-I", "--head":
+		p.method = "HEAD"
+	case "-F", "--form":
+		part, err := ParseFormPart(value)
+		if err != nil {
+			return fmt.Errorf("curl %s: %w", name, err)
+		}
+		p.opts.Form = append(p.opts.Form, part)
+	case "--form-string":
+		k, v, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("curl %s: expected name=value, got %q", name, value)
+		}
+		p.opts.Form = append(p.opts.Form, FormPart{Name: k, Value: v})
+	case "-k", "--insecure":
+		p.opts.TLS.Insecure = true
+	case "--cacert":
+		p.opts.TLS.CACert = value
+	case "-E", "--cert":
+		if cert, pass, ok := strings.Cut(value, ":"); ok && !strings.Contains(pass, `\`) {
+			p.warnf("client certificate passwords are not supported; use an unencrypted key")
+			value = cert
+		}
+		p.opts.TLS.Cert = value
+	case "--key":
+		p.opts.TLS.Key = value
+	case "--retry":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("curl %s: invalid number %q", name, value)
+		}
+		p.opts.Retry.Max = n
+	case "--retry-delay":
+		secs, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("curl %s: invalid number %q", name, value)
+		}
+		p.opts.Retry.Delay = time.Duration(secs * float64(time.Second))
+	case "--max-redirs":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("curl %s: invalid number %q", name, value)
+		}
+		switch {
+		case n == 0:
+			p.opts.NoFollow = true
+		case n < 0:
+			p.opts.MaxRedirects = -1 // curl's "no limit"
+		default:
+			p.opts.MaxRedirects = n
+		}
+	case "-L", "--location":
+		p.location = true
+	case "--location-trusted":
+		p.location = true
+		p.opts.KeepAuth = true
+	case "-x", "--proxy":
+		p.opts.Proxy = value
+	case "-U", "--proxy-user":
+		p.proxyUser = value
+	case "--tlsv1", "--tlsv1.0", "--tlsv1.1", "--tlsv1.2", "--tlsv1.3":
+		p.opts.TLS.MinVersion = strings.TrimPrefix(name, "--tlsv")
+		if p.opts.TLS.MinVersion == "1" {
+			p.opts.TLS.MinVersion = "1.0"
+		}
+	case "--compressed",
+		"-s", "--silent", "-S", "--show-error", "-v", "--verbose", "-i", "--include",
+		"-f", "--fail", "-g", "--globoff", "-N", "--no-buffer", "-#", "--progress-bar",
+		"--http1.1", "--http2", "-4", "-6", "--ipv4", "--ipv6":
+		// Either the default behaviour here or only affects curl's own output.
+	default:
+		if curlFlagTakesValue(name) {
+			p.warnf("option %s %q is not supported; skipped", name, value)
+		} else {
+			p.warnf("option %s is not supported; skipped", name)
+		}
+	}
+	return nil
+}
+
+// finish resolves the method, body, and query from the collected options.
+func (p *curlParser) finish() (RequestOptions, []string, error) {
+	if p.opts.URL == "" {
+		return RequestOptions{}, nil, fmt.Errorf("no URL found in curl command")
+	}
+	// Like the method commands, assume https for a bare host, but leave a
+	// URL starting with a {{placeholder}} alone: it supplies the scheme.
+	if !strings.Contains(p.opts.URL, "://") && !strings.HasPrefix(p.opts.URL, "{{") {
+		p.opts.URL = "https://" + p.opts.URL
+	}
+
+	if p.proxyUser != "" {
+		if p.opts.Proxy == "" {
+			p.warnf("--proxy-user given without --proxy; ignored")
+		} else if u, err := parseProxyURL(p.opts.Proxy); err == nil {
+			user, pass, _ := strings.Cut(p.proxyUser, ":")
+			u.User = url.UserPassword(user, pass)
+			p.opts.Proxy = u.String()
+		}
+	}
+
+	body := strings.Join(p.data, "&")
+	switch {
+	case p.getMode && body != "":
+		sep := "?"
+		if strings.Contains(p.opts.URL, "?") {
+			sep = "&"
+		}
+		p.opts.URL += sep + body
+	case body != "" && len(p.opts.Form) > 0:
+		p.warnf("both -d and -F given; the -d data was dropped")
+	case body != "":
+		p.opts.Body = body
+		if !hasHeader(p.opts.Headers, "Content-Type") {
+			// curl sends -d data as a form unless told otherwise.
+			p.opts.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+	}
+
+	if !p.location {
+		p.opts.NoFollow = true
+	}
+
+	p.opts.Method = p.method
+	if p.opts.Method == "" {
+		p.opts.Method = "GET"
+		if p.opts.Body != "" || len(p.opts.Form) > 0 {
+			p.opts.Method = "POST"
+		}
+	}
+	return p.opts, p.warnings, nil
+}
+
+// curlURLEncode implements --data-urlencode's "content", "=content",
+// "name=content", "@file", and "name@file" forms.
+func curlURLEncode(value string) (string, error) {
+	if name, content, ok := strings.Cut(value, "="); ok {
+		if name == "" {
+			return url.QueryEscape(content), nil
+		}
+		return name + "=" + url.QueryEscape(content), nil
+	}
+	if name, file, ok := strings.Cut(value, "@"); ok {
+		if err := CheckLocalFileAccess("curl --data-urlencode " + value); err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("curl --data-urlencode: %w", err)
+		}
+		if name == "" {
+			return url.QueryEscape(string(data)), nil
+		}
+		return name + "=" + url.QueryEscape(string(data)), nil
+	}
+	return url.QueryEscape(value), nil
+}
+
+// splitShellWords splits a command line the way a POSIX shell would for a
+// simple command: single quotes, double quotes, $'...' strings, backslash
+// escapes, and backslash-newline line continuations are all honoured.
+func splitShellWords(s string) ([]string, error) {
+	var (
+		words  []string
+		cur    strings.Builder
+		inWord bool
+		runes  = []rune(s)
+		flush  = func() {
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		}
+	)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && (runes[i+1] == '\n' || (runes[i+1] == '\r' && i+2 < len(runes) && runes[i+2] == '\n')):
+			// Line continuation.
+			if runes[i+1] == '\r' {
+				i++
+			}
+			i++
+		case r == '\\' && i+1 < len(runes):
+			i++
+			cur.WriteRune(runes[i])
+			inWord = true
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote in curl command")
+			}
+			cur.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			j := i + 2
+			for ; j < len(runes) && runes[j] != '\''; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+					cur.WriteString(ansiCEscape(runes[j]))
+					continue
+				}
+				cur.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated $'...' string in curl command")
+			}
+			inWord = true
+			i = j
+		case r == '"':
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[j+1]) {
+					j++
+					if runes[j] == '\n' {
+						continue
+					}
+				}
+				cur.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated double quote in curl command")
+			}
+			inWord = true
+			i = j
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush()
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	flush()
+	return words, nil
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// ansiCEscape decodes the character following a backslash in a $'...'
+// string.
+func ansiCEscape(r rune) string {
+	switch r {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case 'r':
+		return "\r"
+	case '0':
+		return "\x00"
+	}
+	return string(r)
+}
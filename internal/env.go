@@ -26,6 +26,9 @@ func LoadEnv(filename string) (Env, error) {
 		return Env{}, nil
 	}
 
+	if err := CheckLocalFileRead("env file "+filename, filename); err != nil {
+		return nil, err
+	}
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("could not read env file %q: %w", filename, err)
@@ -53,3 +56,19 @@ func (e Env) Interpolate(input string) string {
 		return match
 	})
 }
+
+// SaveEnv writes the Env map back to a JSON file in the same flat format
+// that LoadEnv reads.
+func SaveEnv(filename string, env Env) error {
+	if err := CheckLocalFileAccess("env file " + filename); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not serialize environment: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("could not write env file %q: %w", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// postmanSchemaV21 is the schema URL written into exported Postman
+// collections.
+const postmanSchemaV21 = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// requestOptionsOf converts a saved request into RequestOptions without
+// interpolating any {{variable}} placeholders.
+func requestOptionsOf(r SavedRequest) RequestOptions {
+	return RequestOptions{
+		Method:   r.Method,
+		URL:      r.FullURL(),
+		Headers:  r.Headers,
+		Body:     r.Body,
+		Form:     r.Form,
+		Auth:     r.Auth,
+		Timeout:  r.Timeout,
+		Timeouts: r.Timeouts,
+		TLS:      r.TLS,
+		Retry:    r.Retry,
+
+		NoFollow:     r.NoFollow,
+		MaxRedirects: r.MaxRedirects,
+		KeepAuth:     r.KeepAuth,
+	}
+}
+
+// ExportPostman renders saved requests as a Postman v2.1 collection. Names
+// containing "/" are placed into matching folders, mirroring the import.
+// {{variable}} placeholders are already Postman's native syntax.
+func ExportPostman(name string, reqs []SavedRequest) ([]byte, error) {
+	pc := postmanCollection{
+		Info: postmanInfo{Name: name, Schema: postmanSchemaV21},
+		Item: []postmanItem{},
+	}
+
+	for _, r := range reqs {
+		parts := strings.Split(r.Name, "/")
+		items := &pc.Item
+		for _, folder := range parts[:len(parts)-1] {
+			items = postmanFolder(items, folder)
+		}
+		*items = append(*items, postmanItem{
+			Name:    parts[len(parts)-1],
+			Request: toPostmanRequest(r),
+		})
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(pc); err != nil {
+		return nil, fmt.Errorf("could not serialize Postman collection: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// postmanFolder returns the item list of the named folder within items,
+// creating the folder if it does not exist yet.
+func postmanFolder(items *[]postmanItem, name string) *[]postmanItem {
+	for i := range *items {
+		if (*items)[i].Request == nil && (*items)[i].Name == name {
+			return &(*items)[i].Item
+		}
+	}
+	*items = append(*items, postmanItem{Name: name, Item: []postmanItem{}})
+	return &(*items)[len(*items)-1].Item
+}
+
+// toPostmanRequest converts a saved request into Postman's request shape.
+func toPostmanRequest(r SavedRequest) *postmanRequest {
+	pr := &postmanRequest{
+		Method: r.Method,
+		Header: []postmanKV{},
+		URL:    postmanURL{Raw: r.FullURL()},
+	}
+
+	for _, k := range sortedKeys(r.Headers) {
+		pr.Header = append(pr.Header, postmanKV{Key: k, Value: r.Headers[k], Type: "text"})
+	}
+
+	if len(r.Form) > 0 {
+		pr.Body = &postmanBody{Mode: "formdata", FormData: []postmanKV{}}
+		for _, p := range r.Form {
+			if p.File != "" {
+				pr.Body.FormData = append(pr.Body.FormData, postmanKV{Key: p.Name, Type: "file", Src: p.File, ContentType: p.ContentType})
+			} else {
+				pr.Body.FormData = append(pr.Body.FormData, postmanKV{Key: p.Name, Value: p.Value, Type: "text"})
+			}
+		}
+	} else if r.Body != "" {
+		pr.Body = &postmanBody{Mode: "raw", Raw: r.Body}
+		if ct := headerValue(r.Headers, "Content-Type"); ct == "" || strings.Contains(ct, "json") {
+			pr.Body.Options = &postmanOptions{}
+			pr.Body.Options.Raw.Language = "json"
+		}
+	}
+
+	if r.Auth != "" {
+		auth := r.Auth
+		if _, plain := plainBasicCredentials(auth); !plain {
+			auth = AuthorizationValue(auth)
+		}
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			pr.Auth = &postmanAuth{Type: "bearer", Bearer: []postmanKV{{Key: "token", Value: token, Type: "string"}}}
+		} else if user, pass, ok := decodeBasic(strings.TrimPrefix(auth, "Basic ")); ok {
+			pr.Auth = &postmanAuth{Type: "basic", Basic: []postmanKV{
+				{Key: "username", Value: user, Type: "string"},
+				{Key: "password", Value: pass, Type: "string"},
+			}}
+		} else {
+			pr.Header = append(pr.Header, postmanKV{Key: "Authorization", Value: auth, Type: "text"})
+		}
+	}
+	return pr
+}
+
+// decodeBasic splits "user:pass" credentials, base64-encoded or not.
+func decodeBasic(creds string) (string, string, bool) {
+	if strings.Contains(creds, ":") {
+		return strings.Cut(creds, ":")
+	}
+	raw, err := base64.StdEncoding.DecodeString(creds)
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(raw), ":")
+}
+
+// ExportCurlScript renders saved requests as a bash script of curl commands.
+// {{variable}} placeholders become shell variables, which the script checks
+// are set before running anything.
+func ExportCurlScript(reqs []SavedRequest) string {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	b.WriteString("# Generated by apitester. Set the variables below in your shell before running.\n")
+	b.WriteString("set -euo pipefail\n\n")
+
+	if vars := referencedVars(reqs); len(vars) > 0 {
+		for _, v := range vars {
+			fmt.Fprintf(&b, ": \"${%s:?variable %s is not set}\"\n", shellVarName(v), shellVarName(v))
+		}
+		b.WriteString("\n")
+	}
+
+	// Variables in a query string are percent-encoded before use, as a
+	// value such as "a&b" would otherwise change the query.
+	encoded := map[string]bool{}
+	opts := make([]RequestOptions, len(reqs))
+	for i, r := range reqs {
+		opts[i] = requestOptionsOf(r)
+		opts[i].URL = encodeQueryVars(opts[i].URL, encoded)
+	}
+	if len(encoded) > 0 {
+		b.WriteString(shellURLEncodeFunc)
+		for _, v := range sortedKeys(encoded) {
+			fmt.Fprintf(&b, "urlencoded_%s=$(urlencode \"${%s}\")\n", shellVarName(v), shellVarName(v))
+		}
+		b.WriteString("\n")
+	}
+
+	for i, r := range reqs {
+		fmt.Fprintf(&b, "# %s\n", r.Name)
+		b.WriteString(curlCommand(opts[i], shellQuoteVars))
+		b.WriteString("\necho\n\n")
+	}
+	return b.String()
+}
+
+// shellURLEncodeFunc is a bash function percent-encoding its argument byte
+// by byte, leaving only RFC 3986 unreserved characters as they are.
+const shellURLEncodeFunc = `urlencode() {
+  local LC_ALL=C s="$1" out="" c i
+  for ((i = 0; i < ${#s}; i++)); do
+    c="${s:i:1}"
+    case "$c" in
+      [A-Za-z0-9._~-]) out+="$c" ;;
+      *) out+=$(printf '%%%02X' "'$c") ;;
+    esac
+  done
+  printf '%s' "$out"
+}
+`
+
+// encodeQueryVars renames {{variable}} placeholders in the query string of
+// rawURL to {{urlencoded_variable}}, recording each name in encoded.
+func encodeQueryVars(rawURL string, encoded map[string]bool) string {
+	q := strings.Index(rawURL, "?")
+	if q < 0 {
+		return rawURL
+	}
+	query := varPattern.ReplaceAllStringFunc(rawURL[q:], func(m string) string {
+		name := strings.TrimSpace(m[2 : len(m)-2])
+		encoded[name] = true
+		return "{{urlencoded_" + shellVarName(name) + "}}"
+	})
+	return rawURL[:q] + query
+}
+
+// ExportHTTPFile renders saved requests in the .http format understood by
+// the VS Code REST Client and JetBrains HTTP Client, both of which use the
+// same {{variable}} syntax as apitester.
+func ExportHTTPFile(reqs []SavedRequest) string {
+	var b strings.Builder
+	for i, r := range reqs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### %s\n", r.Name)
+		fmt.Fprintf(&b, "%s %s\n", r.Method, r.FullURL())
+		if len(r.Form) > 0 {
+			fmt.Fprintf(&b, "Content-Type: multipart/form-data; boundary=%s\n", httpFileBoundary)
+		} else if r.Body != "" && !hasHeader(r.Headers, "Content-Type") {
+			b.WriteString("Content-Type: application/json\n")
+		}
+		for _, k := range sortedKeys(r.Headers) {
+			if len(r.Form) > 0 && strings.EqualFold(k, "Content-Type") {
+				continue
+			}
+			fmt.Fprintf(&b, "%s: %s\n", k, r.Headers[k])
+		}
+		if _, plain := plainBasicCredentials(r.Auth); plain {
+			// REST clients encode "Basic user:pass" themselves, after
+			// filling in variables.
+			fmt.Fprintf(&b, "Authorization: %s\n", r.Auth)
+		} else if r.Auth != "" {
+			fmt.Fprintf(&b, "Authorization: %s\n", AuthorizationValue(r.Auth))
+		}
+		if len(r.Form) > 0 {
+			b.WriteString("\n")
+			writeHTTPFileForm(&b, r.Form)
+		} else if r.Body != "" {
+			fmt.Fprintf(&b, "\n%s\n", r.Body)
+		}
+	}
+	return b.String()
+}
+
+// httpFileBoundary separates multipart parts in exported .http files.
+const httpFileBoundary = "----apitesterFormBoundary"
+
+// writeHTTPFileForm writes multipart parts in .http syntax, where
+// "< path" includes a file's contents.
+func writeHTTPFileForm(b *strings.Builder, parts []FormPart) {
+	for _, p := range parts {
+		fmt.Fprintf(b, "--%s\n", httpFileBoundary)
+		h := p.header()
+		fmt.Fprintf(b, "Content-Disposition: %s\n", h.Get("Content-Disposition"))
+		if p.File != "" {
+			fmt.Fprintf(b, "Content-Type: %s\n\n< %s\n", h.Get("Content-Type"), p.File)
+		} else {
+			fmt.Fprintf(b, "\n%s\n", p.Value)
+		}
+	}
+	fmt.Fprintf(b, "--%s--\n", httpFileBoundary)
+}
+
+// referencedVars returns the sorted, de-duplicated {{variable}} names used
+// anywhere in the given requests.
+func referencedVars(reqs []SavedRequest) []string {
+	seen := map[string]bool{}
+	collect := func(s string) {
+		for _, m := range varPattern.FindAllStringSubmatch(s, -1) {
+			seen[strings.TrimSpace(m[1])] = true
+		}
+	}
+	for _, r := range reqs {
+		collect(r.URL)
+		for k, v := range r.Query {
+			collect(k)
+			collect(v)
+		}
+		collect(r.Body)
+		collect(r.Auth)
+		for _, p := range r.Form {
+			collect(p.Name)
+			collect(p.Value)
+			collect(p.File)
+		}
+		for k, v := range r.Headers {
+			collect(k)
+			collect(v)
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,261 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Histogram range: latencies from 1µs to an hour, recorded in nanoseconds.
+// Anything slower is clamped to an hour.
+const (
+	histogramLowest  = int64(time.Microsecond)
+	histogramHighest = int64(time.Hour)
+
+	// DefaultHistogramPrecision is the number of significant decimal digits
+	// kept when none is given.
+	DefaultHistogramPrecision = 3
+)
+
+// Histogram records latencies in constant memory using the HdrHistogram
+// layout: values are grouped into buckets that double in size, each split
+// into linear sub-buckets, so every recorded value keeps the configured
+// number of significant digits however large it is. Recording is O(1) and
+// memory does not grow with the number of samples, which keeps long soak
+// tests cheap.
+type Histogram struct {
+	precision                   int
+	unitMagnitude               int // log2 of the lowest discernible value
+	subBucketHalfCountMagnitude int
+	subBucketCount              int
+	subBucketHalfCount          int
+	subBucketMask               int64
+	bucketCount                 int
+
+	counts     []int64
+	totalCount int64
+	min, max   int64
+}
+
+// NewHistogram returns an empty histogram keeping precision significant
+// digits (1 to 5). Memory use grows roughly tenfold per extra digit: about
+// 27KB at 2 digits, 200KB at 3, 2.5MB at 4 and 17MB at 5.
+func NewHistogram(precision int) (*Histogram, error) {
+	if precision < 1 || precision > 5 {
+		return nil, fmt.Errorf("histogram precision must be between 1 and 5 significant digits, got %d", precision)
+	}
+
+	h := &Histogram{precision: precision, min: math.MaxInt64}
+	h.unitMagnitude = bits.Len64(uint64(histogramLowest)) - 1
+
+	// Enough sub-buckets to tell apart every value with `precision` digits.
+	largestSingleUnit := 2 * int64(math.Pow10(precision))
+	subBucketCountMagnitude := bits.Len64(uint64(largestSingleUnit - 1))
+	h.subBucketHalfCountMagnitude = max(subBucketCountMagnitude, 1) - 1
+	h.subBucketCount = 1 << (h.subBucketHalfCountMagnitude + 1)
+	h.subBucketHalfCount = h.subBucketCount / 2
+	h.subBucketMask = int64(h.subBucketCount-1) << h.unitMagnitude
+
+	// Add doubling buckets until the highest value fits.
+	smallestUntrackable := int64(h.subBucketCount) << h.unitMagnitude
+	h.bucketCount = 1
+	for smallestUntrackable <= histogramHighest {
+		smallestUntrackable <<= 1
+		h.bucketCount++
+	}
+
+	h.counts = make([]int64, (h.bucketCount+1)*h.subBucketHalfCount)
+	return h, nil
+}
+
+// mustHistogram is NewHistogram for precisions already validated.
+func mustHistogram(precision int) *Histogram {
+	h, err := NewHistogram(precision)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// Record adds one latency.
+func (h *Histogram) Record(d time.Duration) {
+	v := min(max(int64(d), 0), histogramHighest)
+	h.counts[h.countsIndex(v)]++
+	h.totalCount++
+	h.min = min(h.min, v)
+	h.max = max(h.max, v)
+}
+
+// Merge adds every value recorded in other, which must have the same
+// precision.
+func (h *Histogram) Merge(other *Histogram) {
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	h.totalCount += other.totalCount
+	h.min = min(h.min, other.min)
+	h.max = max(h.max, other.max)
+}
+
+// Reset empties the histogram.
+func (h *Histogram) Reset() {
+	clear(h.counts)
+	h.totalCount = 0
+	h.min, h.max = math.MaxInt64, 0
+}
+
+// Count returns the number of recorded values.
+func (h *Histogram) Count() int64 { return h.totalCount }
+
+// Min returns the smallest recorded value, or 0 if there are none.
+func (h *Histogram) Min() time.Duration {
+	if h.totalCount == 0 {
+		return 0
+	}
+	return time.Duration(h.lowestEquivalent(h.min))
+}
+
+// Max returns the largest recorded value, exactly, or 0 if there are none.
+func (h *Histogram) Max() time.Duration {
+	return time.Duration(h.max)
+}
+
+// Mean returns the average recorded value.
+func (h *Histogram) Mean() time.Duration {
+	if h.totalCount == 0 {
+		return 0
+	}
+	var total float64
+	h.each(func(v, count int64) {
+		total += float64(h.medianEquivalent(v)) * float64(count)
+	})
+	return time.Duration(total / float64(h.totalCount))
+}
+
+// StdDev returns the standard deviation of the recorded values.
+func (h *Histogram) StdDev() time.Duration {
+	if h.totalCount == 0 {
+		return 0
+	}
+	mean := float64(h.Mean())
+	var sq float64
+	h.each(func(v, count int64) {
+		dev := float64(h.medianEquivalent(v)) - mean
+		sq += dev * dev * float64(count)
+	})
+	return time.Duration(math.Sqrt(sq / float64(h.totalCount)))
+}
+
+// Percentile returns the value at or below which p percent of recorded
+// values fall.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.totalCount == 0 {
+		return 0
+	}
+	p = min(max(p, 0), 100)
+	target := max(int64(p/100*float64(h.totalCount)+0.5), 1)
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= target {
+			return time.Duration(min(h.highestEquivalent(h.valueFromIndex(i)), h.max))
+		}
+	}
+	return time.Duration(h.max)
+}
+
+// each calls fn with the lowest value and count of every non-empty
+// sub-bucket, in increasing order.
+func (h *Histogram) each(fn func(v, count int64)) {
+	for i, c := range h.counts {
+		if c > 0 {
+			fn(h.valueFromIndex(i), c)
+		}
+	}
+}
+
+// countAtOrBelow returns how many recorded values are at most v.
+func (h *Histogram) countAtOrBelow(v int64) int64 {
+	last := h.countsIndex(min(v, histogramHighest))
+	var n int64
+	for _, c := range h.counts[:last+1] {
+		n += c
+	}
+	return n
+}
+
+func (h *Histogram) bucketIndex(v int64) int {
+	pow2Ceiling := bits.Len64(uint64(v | h.subBucketMask))
+	return pow2Ceiling - h.unitMagnitude - (h.subBucketHalfCountMagnitude + 1)
+}
+
+func (h *Histogram) countsIndex(v int64) int {
+	bucket := h.bucketIndex(v)
+	sub := int(v >> (bucket + h.unitMagnitude))
+	return ((bucket + 1) << h.subBucketHalfCountMagnitude) + (sub - h.subBucketHalfCount)
+}
+
+// valueFromIndex returns the lowest value counted at counts[i].
+func (h *Histogram) valueFromIndex(i int) int64 {
+	bucket := (i >> h.subBucketHalfCountMagnitude) - 1
+	sub := (i & (h.subBucketHalfCount - 1)) + h.subBucketHalfCount
+	if bucket < 0 {
+		sub -= h.subBucketHalfCount
+		bucket = 0
+	}
+	return int64(sub) << (bucket + h.unitMagnitude)
+}
+
+// equivalentRange returns the width of the sub-bucket holding v.
+func (h *Histogram) equivalentRange(v int64) int64 {
+	bucket := h.bucketIndex(v)
+	sub := int(v >> (bucket + h.unitMagnitude))
+	if sub >= h.subBucketCount {
+		bucket++
+	}
+	return 1 << (h.unitMagnitude + bucket)
+}
+
+func (h *Histogram) lowestEquivalent(v int64) int64 {
+	return h.valueFromIndex(h.countsIndex(v))
+}
+
+func (h *Histogram) highestEquivalent(v int64) int64 {
+	return h.lowestEquivalent(v) + h.equivalentRange(v) - 1
+}
+
+func (h *Histogram) medianEquivalent(v int64) int64 {
+	return h.lowestEquivalent(v) + h.equivalentRange(v)/2
+}
+
+// WritePercentiles writes the percentile distribution in HdrHistogram's
+// text (.hgrm) format, values in milliseconds, as read by HdrHistogram
+// plotters. Percentile steps halve in size each time the distance to 100%
+// halves, five steps per halving.
+func (h *Histogram) WritePercentiles(w io.Writer) error {
+	const (
+		ticksPerHalfDistance = 5
+		scale                = float64(time.Millisecond)
+	)
+	ms := func(d time.Duration) float64 { return float64(d) / scale }
+
+	fmt.Fprintf(w, "%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)")
+	if h.totalCount > 0 {
+		for p := 0.0; p < 100; {
+			v := h.Percentile(p)
+			if int64(v) >= h.max {
+				break
+			}
+			fmt.Fprintf(w, "%12.3f %2.12f %10d %14.2f\n", ms(v), p/100, h.countAtOrBelow(int64(v)), 1/(1-p/100))
+			halvings := math.Floor(math.Log2(100/(100-p))) + 1
+			p += 100 / (ticksPerHalfDistance * math.Pow(2, halvings))
+		}
+		fmt.Fprintf(w, "%12.3f %2.12f %10d\n", ms(h.Max()), 1.0, h.totalCount)
+	}
+	fmt.Fprintf(w, "#[Mean    = %12.3f, StdDeviation   = %12.3f]\n", ms(h.Mean()), ms(h.StdDev()))
+	fmt.Fprintf(w, "#[Max     = %12.3f, Total count    = %12d]\n", ms(h.Max()), h.totalCount)
+	_, err := fmt.Fprintf(w, "#[Buckets = %12d, SubBuckets     = %12d]\n", h.bucketCount, h.subBucketCount)
+	return err
+}
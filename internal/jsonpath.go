@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EvalJSONPath resolves a simple JSONPath expression against a JSON document.
+//
+// Supported syntax covers the common cases used in assertions and captures:
+//
+//	$                 the whole document
+//	$.user.name       object members
+//	$['user-id']      quoted members (single or double quotes)
+//	$.items[0].id     array indices
+//	$.items[-1]       negative indices count from the end
+//	$.items.length    the length of an array, object, or string
+//
+// The boolean result reports whether the path exists in the document.
+func EvalJSONPath(body []byte, path string) (interface{}, bool, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, false, fmt.Errorf("response body is not valid JSON: %w", err)
+	}
+
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cur := doc
+	for _, step := range steps {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			v, ok := node[step.key]
+			if !ok {
+				if step.key == "length" && !step.isIndex {
+					cur = float64(len(node))
+					continue
+				}
+				return nil, false, nil
+			}
+			cur = v
+		case []interface{}:
+			if !step.isIndex {
+				if step.key == "length" {
+					cur = float64(len(node))
+					continue
+				}
+				return nil, false, nil
+			}
+			idx := step.index
+			if idx < 0 {
+				idx += len(node)
+			}
+			if idx < 0 || idx >= len(node) {
+				return nil, false, nil
+			}
+			cur = node[idx]
+		case string:
+			if step.key == "length" && !step.isIndex {
+				cur = float64(len([]rune(node)))
+				continue
+			}
+			return nil, false, nil
+		default:
+			return nil, false, nil
+		}
+	}
+	return cur, true, nil
+}
+
+// jsonPathStep is a single member or index access in a parsed JSONPath.
+type jsonPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath splits a JSONPath expression into member and index steps.
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	p := strings.TrimSpace(path)
+	if !strings.HasPrefix(p, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with '$'", path)
+	}
+	p = p[1:]
+
+	var steps []jsonPathStep
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty member name", path)
+			}
+			steps = append(steps, jsonPathStep{key: p[:end]})
+			p = p[end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: unclosed '['", path)
+			}
+			inner := strings.TrimSpace(p[1:end])
+			p = p[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, jsonPathStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSONPath %q: bad index %q", path, inner)
+			}
+			steps = append(steps, jsonPathStep{index: idx, isIndex: true})
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q", path, p[0])
+		}
+	}
+	return steps, nil
+}
+
+// jsonValueString renders a value returned by EvalJSONPath as text. Strings
+// are returned unquoted; everything else is rendered as compact JSON.
+func jsonValueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
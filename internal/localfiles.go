@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NoLocalFilesEnv names an environment variable that, when set to anything,
+// stops apitester from reading or writing files named on its command line:
+// request bodies (--body @file, --body -, -F name=@file, curl -d @file),
+// --env and --save-env, --openapi, --stages files, TLS certificates and
+// keys, imported Postman files, --vars-out and --env-out, --cookie-jar,
+// collection export -o and stress --hdr-out. The web terminal sets it so
+// visitors can neither send the server's files elsewhere nor overwrite
+// them. apitester's own collection store under ~/.apitester is still used.
+const NoLocalFilesEnv = "APITESTER_NO_LOCAL_FILES"
+
+// AllowedFilesEnv names an environment variable holding a comma-separated
+// list of files that may still be read (never written) while
+// NoLocalFilesEnv is set, such as the web terminal's demo --env file.
+const AllowedFilesEnv = "APITESTER_ALLOW_FILES"
+
+// CheckLocalFileAccess returns an error naming what was asked for when
+// NoLocalFilesEnv turns local file access off.
+func CheckLocalFileAccess(what string) error {
+	if os.Getenv(NoLocalFilesEnv) != "" {
+		return fmt.Errorf("%s: local file access is disabled (%s is set)", what, NoLocalFilesEnv)
+	}
+	return nil
+}
+
+// CheckLocalFileRead is CheckLocalFileAccess for reading path, which is
+// allowed anyway when AllowedFilesEnv lists it.
+func CheckLocalFileRead(what, path string) error {
+	err := CheckLocalFileAccess(what)
+	if err == nil {
+		return nil
+	}
+	for _, allowed := range strings.Split(os.Getenv(AllowedFilesEnv), ",") {
+		if allowed = strings.TrimSpace(allowed); allowed != "" && filepath.Clean(allowed) == filepath.Clean(path) {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FormPart is one field of a multipart/form-data body: either a plain
+// value or a file uploaded from disk.
+//
+// On the command line parts use curl's -F syntax:
+//
+//	name=value                     text field
+//	name=@path/to/file             file upload
+//	name=@photo.png;type=image/png file upload with an explicit content type
+//	name=@data.bin;filename=x.bin  file upload sent under another file name
+//	name=<notes.txt                text field read from a file
+type FormPart struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	File        string `json:"file,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	ContentType string `json:"type,omitempty"`
+}
+
+// ParseFormParts parses a list of -F/--form values.
+func ParseFormParts(specs []string) ([]FormPart, error) {
+	parts := make([]FormPart, 0, len(specs))
+	for _, s := range specs {
+		p, err := ParseFormPart(s)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, p)
+	}
+	return parts, nil
+}
+
+// ParseFormPart parses a single -F/--form value.
+func ParseFormPart(spec string) (FormPart, error) {
+	name, value, ok := strings.Cut(spec, "=")
+	if !ok || name == "" {
+		return FormPart{}, fmt.Errorf("invalid form field %q: expected name=value or name=@file", spec)
+	}
+	p := FormPart{Name: name}
+
+	switch {
+	case strings.HasPrefix(value, "@"):
+		fields := strings.Split(value[1:], ";")
+		p.File = fields[0]
+		for _, attr := range fields[1:] {
+			k, v, _ := strings.Cut(attr, "=")
+			switch strings.TrimSpace(k) {
+			case "type":
+				p.ContentType = v
+			case "filename":
+				p.Filename = v
+			default:
+				return FormPart{}, fmt.Errorf("invalid form field %q: unknown attribute %q", spec, k)
+			}
+		}
+		if p.File == "" {
+			return FormPart{}, fmt.Errorf("invalid form field %q: missing file path after @", spec)
+		}
+	case strings.HasPrefix(value, "<"):
+		if err := CheckLocalFileAccess("form field " + spec); err != nil {
+			return FormPart{}, err
+		}
+		data, err := os.ReadFile(value[1:])
+		if err != nil {
+			return FormPart{}, fmt.Errorf("invalid form field %q: %w", spec, err)
+		}
+		p.Value = string(data)
+	default:
+		p.Value = value
+	}
+	return p, nil
+}
+
+// String renders the part back into -F syntax.
+func (p FormPart) String() string {
+	if p.File == "" {
+		return p.Name + "=" + p.Value
+	}
+	s := p.Name + "=@" + p.File
+	if p.ContentType != "" {
+		s += ";type=" + p.ContentType
+	}
+	if p.Filename != "" {
+		s += ";filename=" + p.Filename
+	}
+	return s
+}
+
+// filename is the name the file is uploaded under.
+func (p FormPart) filename() string {
+	if p.Filename != "" {
+		return p.Filename
+	}
+	return filepath.Base(p.File)
+}
+
+// header builds the MIME header for the part.
+func (p FormPart) header() textproto.MIMEHeader {
+	h := textproto.MIMEHeader{}
+	if p.File == "" {
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(p.Name)))
+		return h
+	}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(p.Name), escapeQuotes(p.filename())))
+	ct := p.ContentType
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	h.Set("Content-Type", ct)
+	return h
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct{ n int64 }
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// multipartBody returns a reader that streams the parts as a
+// multipart/form-data body, along with its content type and exact length.
+// Files are read as the body is sent rather than loaded into memory.
+func multipartBody(parts []FormPart) (io.ReadCloser, string, int64, error) {
+	// Size every file first, so missing files are reported before anything
+	// is sent and the Content-Length can be computed.
+	var fileBytes int64
+	for _, p := range parts {
+		if p.File == "" {
+			continue
+		}
+		if err := CheckLocalFileAccess(fmt.Sprintf("form field %q", p.Name)); err != nil {
+			return nil, "", 0, err
+		}
+		info, err := os.Stat(p.File)
+		if err != nil {
+			return nil, "", 0, fmt.Errorf("form field %q: %w", p.Name, err)
+		}
+		if info.IsDir() {
+			return nil, "", 0, fmt.Errorf("form field %q: %s is a directory", p.Name, p.File)
+		}
+		fileBytes += info.Size()
+	}
+
+	// Dry run with the same boundary to measure everything but file data.
+	counter := &countingWriter{}
+	mw := multipart.NewWriter(counter)
+	for _, p := range parts {
+		w, err := mw.CreatePart(p.header())
+		if err != nil {
+			return nil, "", 0, err
+		}
+		io.WriteString(w, p.Value)
+	}
+	mw.Close()
+	length := counter.n + fileBytes
+
+	pr, pw := io.Pipe()
+	out := multipart.NewWriter(pw)
+	if err := out.SetBoundary(mw.Boundary()); err != nil {
+		return nil, "", 0, err
+	}
+	go func() {
+		pw.CloseWithError(writeParts(out, parts))
+	}()
+	return pr, mw.FormDataContentType(), length, nil
+}
+
+// writeParts writes every part and the closing boundary.
+func writeParts(mw *multipart.Writer, parts []FormPart) error {
+	for _, p := range parts {
+		w, err := mw.CreatePart(p.header())
+		if err != nil {
+			return err
+		}
+		if p.File == "" {
+			if _, err := io.WriteString(w, p.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		f, err := os.Open(p.File)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
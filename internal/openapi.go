@@ -0,0 +1,675 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPISpec is a parsed OpenAPI 3.0 or 3.1 document, limited to the parts
+// used to generate and validate requests.
+type OpenAPISpec struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       openAPIInfo                `json:"info"`
+	Servers    []openAPIServer            `json:"servers"`
+	Paths      map[string]openAPIPathItem `json:"paths"`
+	Components openAPIComponents          `json:"components"`
+	Security   []map[string][]string      `json:"security"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL       string `json:"url"`
+	Variables map[string]struct {
+		Default string `json:"default"`
+	} `json:"variables"`
+}
+
+type openAPIPathItem struct {
+	Parameters []openAPIParameter `json:"parameters"`
+	Get        *openAPIOperation  `json:"get"`
+	Put        *openAPIOperation  `json:"put"`
+	Post       *openAPIOperation  `json:"post"`
+	Delete     *openAPIOperation  `json:"delete"`
+	Options    *openAPIOperation  `json:"options"`
+	Head       *openAPIOperation  `json:"head"`
+	Patch      *openAPIOperation  `json:"patch"`
+	Trace      *openAPIOperation  `json:"trace"`
+}
+
+// operations returns the path item's operations keyed by upper-case method,
+// in a stable order.
+func (p openAPIPathItem) operations() []methodOperation {
+	all := []methodOperation{
+		{"GET", p.Get}, {"POST", p.Post}, {"PUT", p.Put}, {"PATCH", p.Patch},
+		{"DELETE", p.Delete}, {"HEAD", p.Head}, {"OPTIONS", p.Options}, {"TRACE", p.Trace},
+	}
+	ops := all[:0]
+	for _, o := range all {
+		if o.op != nil {
+			ops = append(ops, o)
+		}
+	}
+	return ops
+}
+
+type methodOperation struct {
+	method string
+	op     *openAPIOperation
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Tags        []string                    `json:"tags"`
+	Parameters  []openAPIParameter          `json:"parameters"`
+	RequestBody *openAPIRequestBody         `json:"requestBody"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+	Security    *[]map[string][]string      `json:"security"`
+}
+
+type openAPIParameter struct {
+	Ref      string                    `json:"$ref"`
+	Name     string                    `json:"name"`
+	In       string                    `json:"in"`
+	Required bool                      `json:"required"`
+	Schema   *openAPISchema            `json:"schema"`
+	Example  interface{}               `json:"example"`
+	Examples map[string]openAPIExample `json:"examples"`
+}
+
+type openAPIRequestBody struct {
+	Ref      string                      `json:"$ref"`
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema   *openAPISchema             `json:"schema"`
+	Example  interface{}                `json:"example"`
+	Examples map[string]openAPIExample  `json:"examples"`
+	Encoding map[string]openAPIEncoding `json:"encoding"`
+}
+
+// openAPIEncoding describes how one multipart property is sent.
+type openAPIEncoding struct {
+	ContentType string `json:"contentType"`
+}
+
+type openAPIExample struct {
+	Ref   string      `json:"$ref"`
+	Value interface{} `json:"value"`
+}
+
+type openAPIResponse struct {
+	Ref     string                      `json:"$ref"`
+	Headers map[string]*openAPIHeader   `json:"headers"`
+	Content map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIHeader struct {
+	Ref      string         `json:"$ref"`
+	Required bool           `json:"required"`
+	Schema   *openAPISchema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema        `json:"schemas"`
+	Parameters      map[string]openAPIParameter      `json:"parameters"`
+	RequestBodies   map[string]openAPIRequestBody    `json:"requestBodies"`
+	Responses       map[string]*openAPIResponse      `json:"responses"`
+	Headers         map[string]*openAPIHeader        `json:"headers"`
+	Examples        map[string]openAPIExample        `json:"examples"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Ref    string `json:"$ref"`
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+	Name   string `json:"name"`
+	In     string `json:"in"`
+}
+
+// openAPISchema is the subset of JSON Schema used by OpenAPI 3.0 and 3.1.
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref"`
+	Type                 schemaType                `json:"type"`
+	Nullable             bool                      `json:"nullable"`
+	Format               string                    `json:"format"`
+	ContentMediaType     string                    `json:"contentMediaType"`
+	Enum                 []interface{}             `json:"enum"`
+	Const                interface{}               `json:"const"`
+	Default              interface{}               `json:"default"`
+	Example              interface{}               `json:"example"`
+	Examples             interface{}               `json:"examples"`
+	Properties           map[string]*openAPISchema `json:"properties"`
+	Required             []string                  `json:"required"`
+	AdditionalProperties *schemaOrBool             `json:"additionalProperties"`
+	Items                *openAPISchema            `json:"items"`
+	AllOf                []*openAPISchema          `json:"allOf"`
+	AnyOf                []*openAPISchema          `json:"anyOf"`
+	OneOf                []*openAPISchema          `json:"oneOf"`
+	Minimum              *float64                  `json:"minimum"`
+	Maximum              *float64                  `json:"maximum"`
+	ExclusiveMinimum     json.RawMessage           `json:"exclusiveMinimum"`
+	ExclusiveMaximum     json.RawMessage           `json:"exclusiveMaximum"`
+	MultipleOf           *float64                  `json:"multipleOf"`
+	MinLength            *int                      `json:"minLength"`
+	MaxLength            *int                      `json:"maxLength"`
+	Pattern              string                    `json:"pattern"`
+	MinItems             *int                      `json:"minItems"`
+	MaxItems             *int                      `json:"maxItems"`
+	UniqueItems          bool                      `json:"uniqueItems"`
+}
+
+// schemaType holds the JSON Schema "type" keyword, which is a string in
+// OpenAPI 3.0 and may be a list of strings in 3.1.
+type schemaType []string
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var one string
+	if json.Unmarshal(data, &one) == nil {
+		*t = schemaType{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("schema type must be a string or list of strings")
+	}
+	*t = many
+	return nil
+}
+
+// has reports whether the type list includes name.
+func (t schemaType) has(name string) bool {
+	return containsString(t, name)
+}
+
+// first returns the first non-null type, or "" if none is declared.
+func (t schemaType) first() string {
+	for _, v := range t {
+		if v != "null" {
+			return v
+		}
+	}
+	return ""
+}
+
+// schemaOrBool holds additionalProperties, which is either a boolean or a
+// schema.
+type schemaOrBool struct {
+	Allowed bool
+	Schema  *openAPISchema
+}
+
+func (s *schemaOrBool) UnmarshalJSON(data []byte) error {
+	if json.Unmarshal(data, &s.Allowed) == nil {
+		return nil
+	}
+	s.Allowed = true
+	return json.Unmarshal(data, &s.Schema)
+}
+
+// LoadOpenAPI reads an OpenAPI 3.x document in YAML or JSON format.
+func LoadOpenAPI(filename string) (*OpenAPISpec, error) {
+	if err := CheckLocalFileRead("OpenAPI spec "+filename, filename); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read OpenAPI spec %q: %w", filename, err)
+	}
+
+	if ext := strings.ToLower(filepath.Ext(filename)); ext != ".json" {
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML in OpenAPI spec %q: %w", filename, err)
+		}
+		if data, err = json.Marshal(normalizeYAML(doc)); err != nil {
+			return nil, fmt.Errorf("could not convert OpenAPI spec %q: %w", filename, err)
+		}
+	}
+
+	var spec OpenAPISpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec %q: %w", filename, err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return nil, fmt.Errorf("%q is not an OpenAPI 3.x document (openapi: %q)", filename, spec.OpenAPI)
+	}
+	return &spec, nil
+}
+
+// normalizeYAML converts a decoded YAML tree into values encoding/json can
+// marshal: mapping keys such as response codes become strings and
+// timestamps become their textual form.
+func normalizeYAML(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for k, child := range node {
+			node[k] = normalizeYAML(child)
+		}
+		return node
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(node))
+		for k, child := range node {
+			m[fmt.Sprint(k)] = normalizeYAML(child)
+		}
+		return m
+	case []interface{}:
+		for i, child := range node {
+			node[i] = normalizeYAML(child)
+		}
+		return node
+	case time.Time:
+		if node.Hour() == 0 && node.Minute() == 0 && node.Second() == 0 && node.Nanosecond() == 0 {
+			return node.Format("2006-01-02")
+		}
+		return node.Format(time.RFC3339)
+	}
+	return v
+}
+
+// ── $ref resolution ───────────────────────────────────────────────────────────
+
+// refName returns the component name of a local reference such as
+// "#/components/schemas/User" when it points into the given section.
+func refName(ref, section string) (string, bool) {
+	return strings.CutPrefix(ref, "#/components/"+section+"/")
+}
+
+// schema resolves a schema reference chain.
+func (spec *OpenAPISpec) schema(s *openAPISchema) *openAPISchema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		name, ok := refName(s.Ref, "schemas")
+		if !ok {
+			return nil
+		}
+		s = spec.Components.Schemas[name]
+	}
+	return s
+}
+
+func (spec *OpenAPISpec) parameter(p openAPIParameter) openAPIParameter {
+	if name, ok := refName(p.Ref, "parameters"); ok {
+		return spec.Components.Parameters[name]
+	}
+	return p
+}
+
+func (spec *OpenAPISpec) requestBody(b *openAPIRequestBody) *openAPIRequestBody {
+	if b == nil {
+		return nil
+	}
+	if name, ok := refName(b.Ref, "requestBodies"); ok {
+		rb := spec.Components.RequestBodies[name]
+		return &rb
+	}
+	return b
+}
+
+func (spec *OpenAPISpec) example(e openAPIExample) interface{} {
+	if name, ok := refName(e.Ref, "examples"); ok {
+		return spec.Components.Examples[name].Value
+	}
+	return e.Value
+}
+
+// ── import ────────────────────────────────────────────────────────────────────
+
+// OpenAPIImport is the result of converting an OpenAPI document.
+type OpenAPIImport struct {
+	Title    string
+	BaseURL  string
+	Requests []SavedRequest
+	Warnings []string
+}
+
+// openAPIPathParam matches {param} templates in an OpenAPI path.
+var openAPIPathParam = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// nonNameChars matches characters replaced when deriving request names.
+var nonNameChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// ImportOpenAPI creates one saved request per operation in the spec. The
+// server URL becomes {{base_url}}, path parameters become {{param}}
+// placeholders, request bodies are filled from examples or generated from
+// their schemas, and security schemes are mapped onto Auth and headers.
+func ImportOpenAPI(spec *OpenAPISpec) OpenAPIImport {
+	imp := OpenAPIImport{Title: spec.Info.Title}
+	if len(spec.Servers) > 0 {
+		imp.BaseURL = spec.serverURL(spec.Servers[0])
+		if len(spec.Servers) > 1 {
+			imp.Warnings = append(imp.Warnings, fmt.Sprintf("spec lists %d servers; using %s as base_url", len(spec.Servers), imp.BaseURL))
+		}
+	}
+
+	paths := sortedKeys(spec.Paths)
+	for _, path := range paths {
+		item := spec.Paths[path]
+		for _, mo := range item.operations() {
+			imp.Requests = append(imp.Requests, imp.convertOperation(spec, path, mo.method, item, mo.op))
+		}
+	}
+	return imp
+}
+
+// serverURL expands server variables to their defaults and strips any
+// trailing slash.
+func (spec *OpenAPISpec) serverURL(s openAPIServer) string {
+	u := s.URL
+	for name, v := range s.Variables {
+		u = strings.ReplaceAll(u, "{"+name+"}", v.Default)
+	}
+	return strings.TrimSuffix(u, "/")
+}
+
+// convertOperation builds a saved request for a single operation.
+func (imp *OpenAPIImport) convertOperation(spec *OpenAPISpec, path, method string, item openAPIPathItem, op *openAPIOperation) SavedRequest {
+	name := op.OperationID
+	if name == "" {
+		name = strings.ToLower(method) + "-" + strings.Trim(nonNameChars.ReplaceAllString(path, "-"), "-")
+	}
+
+	req := SavedRequest{
+		Name:    name,
+		Method:  method,
+		URL:     "{{base_url}}" + openAPIPathParam.ReplaceAllString(path, "{{$1}}"),
+		Headers: map[string]string{},
+		Tags:    op.Tags,
+	}
+
+	// Operation parameters override path-level ones with the same name and location.
+	params := map[string]openAPIParameter{}
+	var order []string
+	for _, p := range append(append([]openAPIParameter{}, item.Parameters...), op.Parameters...) {
+		p = spec.parameter(p)
+		key := p.In + ":" + p.Name
+		if _, seen := params[key]; !seen {
+			order = append(order, key)
+		}
+		params[key] = p
+	}
+
+	for _, key := range order {
+		p := params[key]
+		if !p.Required {
+			continue
+		}
+		value := "{{" + p.Name + "}}"
+		if ex := spec.parameterExample(p); ex != nil {
+			value = jsonValueString(ex)
+		}
+		switch p.In {
+		case "query":
+			if req.Query == nil {
+				req.Query = map[string]string{}
+			}
+			req.Query[p.Name] = value
+		case "header":
+			req.Headers[p.Name] = value
+		case "cookie":
+			imp.warnf(name, "required cookie parameter %q is not supported", p.Name)
+		}
+	}
+	if rb := spec.requestBody(op.RequestBody); rb != nil {
+		imp.convertBody(spec, &req, rb)
+	}
+
+	security := spec.Security
+	if op.Security != nil {
+		security = *op.Security
+	}
+	imp.convertSecurity(spec, &req, security)
+
+	if len(req.Headers) == 0 {
+		req.Headers = nil
+	}
+	return req
+}
+
+// parameterExample returns the documented example for a parameter, if any.
+func (spec *OpenAPISpec) parameterExample(p openAPIParameter) interface{} {
+	if p.Example != nil {
+		return p.Example
+	}
+	if keys := sortedKeys(p.Examples); len(keys) > 0 {
+		return spec.example(p.Examples[keys[0]])
+	}
+	if s := spec.schema(p.Schema); s != nil {
+		if s.Example != nil {
+			return s.Example
+		}
+		if s.Default != nil {
+			return s.Default
+		}
+	}
+	return nil
+}
+
+// convertBody picks a media type from the request body and fills in an
+// example body for it.
+func (imp *OpenAPIImport) convertBody(spec *OpenAPISpec, req *SavedRequest, rb *openAPIRequestBody) {
+	mediaTypes := sortedKeys(rb.Content)
+	if len(mediaTypes) == 0 {
+		return
+	}
+	chosen := mediaTypes[0]
+	for _, mt := range mediaTypes {
+		if strings.Contains(mt, "json") {
+			chosen = mt
+			break
+		}
+	}
+	media := rb.Content[chosen]
+
+	example := media.Example
+	if keys := sortedKeys(media.Examples); example == nil && len(keys) > 0 {
+		example = spec.example(media.Examples[keys[0]])
+	}
+	if example == nil {
+		example = spec.exampleFromSchema(media.Schema, 0)
+	}
+
+	switch {
+	case strings.Contains(chosen, "json"):
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(example); err != nil {
+			imp.warnf(req.Name, "could not build example body: %v", err)
+			return
+		}
+		req.Body = strings.TrimSpace(buf.String())
+		if chosen != "application/json" {
+			req.Headers["Content-Type"] = chosen
+		}
+	case chosen == "application/x-www-form-urlencoded":
+		fields, _ := example.(map[string]interface{})
+		var parts []string
+		for _, k := range sortedKeys(fields) {
+			parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(jsonValueString(fields[k])))
+		}
+		req.Body = strings.Join(parts, "&")
+		req.Headers["Content-Type"] = chosen
+	case chosen == "multipart/form-data":
+		imp.convertMultipart(spec, req, media, example)
+	case strings.HasPrefix(chosen, "multipart/"):
+		imp.warnf(req.Name, "%s request bodies are not supported; body skipped", chosen)
+	default:
+		if s, ok := example.(string); ok {
+			req.Body = s
+		} else {
+			imp.warnf(req.Name, "no example for %s request body; body left empty", chosen)
+		}
+		req.Headers["Content-Type"] = chosen
+	}
+}
+
+// convertMultipart turns a multipart/form-data schema into form parts.
+// Binary properties become file uploads with a {{name_file}} placeholder
+// path; everything else is sent as a text field with an example value.
+func (imp *OpenAPIImport) convertMultipart(spec *OpenAPISpec, req *SavedRequest, media openAPIMediaType, example interface{}) {
+	schema := spec.schema(media.Schema)
+	fields, _ := example.(map[string]interface{})
+	if schema == nil || len(schema.Properties) == 0 {
+		imp.warnf(req.Name, "multipart request body has no properties; body left empty")
+		return
+	}
+
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := spec.schema(schema.Properties[name])
+		if prop == nil {
+			continue
+		}
+		isFile := prop.Format == "binary" || prop.Format == "base64" || prop.ContentMediaType != ""
+		if items := spec.schema(prop.Items); prop.Type.has("array") && items != nil && items.Format == "binary" {
+			isFile = true
+		}
+		if isFile {
+			part := FormPart{Name: name, File: "{{" + name + "_file}}"}
+			if enc, ok := media.Encoding[name]; ok && !strings.Contains(enc.ContentType, ",") {
+				part.ContentType = enc.ContentType
+			} else if prop.ContentMediaType != "" {
+				part.ContentType = prop.ContentMediaType
+			}
+			req.Form = append(req.Form, part)
+			continue
+		}
+		value := ""
+		if v, ok := fields[name]; ok && v != nil {
+			value = jsonValueString(v)
+		}
+		req.Form = append(req.Form, FormPart{Name: name, Value: value})
+	}
+}
+
+// exampleFromSchema builds an example value for a schema, preferring any
+// example, default, enum, or const it declares.
+func (spec *OpenAPISpec) exampleFromSchema(s *openAPISchema, depth int) interface{} {
+	s = spec.schema(s)
+	if s == nil || depth > 8 {
+		return nil
+	}
+	examples, _ := s.Examples.([]interface{})
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case len(examples) > 0:
+		return examples[0]
+	case s.Default != nil:
+		return s.Default
+	case s.Const != nil:
+		return s.Const
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	}
+
+	if len(s.AllOf) > 0 {
+		merged := map[string]interface{}{}
+		for _, sub := range s.AllOf {
+			if obj, ok := spec.exampleFromSchema(sub, depth+1).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	if len(s.OneOf) > 0 {
+		return spec.exampleFromSchema(s.OneOf[0], depth+1)
+	}
+	if len(s.AnyOf) > 0 {
+		return spec.exampleFromSchema(s.AnyOf[0], depth+1)
+	}
+
+	typ := s.Type.first()
+	if typ == "" && s.Properties != nil {
+		typ = "object"
+	}
+	switch typ {
+	case "object":
+		obj := map[string]interface{}{}
+		for name, prop := range s.Properties {
+			obj[name] = spec.exampleFromSchema(prop, depth+1)
+		}
+		return obj
+	case "array":
+		return []interface{}{spec.exampleFromSchema(s.Items, depth+1)}
+	case "integer", "number":
+		if s.Minimum != nil {
+			return *s.Minimum
+		}
+		return 0
+	case "boolean":
+		return false
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "date":
+			return "2024-01-01"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		return "string"
+	}
+	return nil
+}
+
+// convertSecurity maps the first usable security requirement onto the
+// request: bearer and OAuth schemes use Auth, basic uses Basic credentials,
+// and API keys become headers or query parameters.
+func (imp *OpenAPIImport) convertSecurity(spec *OpenAPISpec, req *SavedRequest, requirements []map[string][]string) {
+	for _, requirement := range requirements {
+		for _, schemeName := range sortedKeys(requirement) {
+			scheme, ok := spec.Components.SecuritySchemes[schemeName]
+			if !ok {
+				imp.warnf(req.Name, "security scheme %q is not defined", schemeName)
+				continue
+			}
+			switch {
+			case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+				req.Auth = "Bearer {{token}}"
+			case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+				req.Auth = "Basic {{basic_credentials}}"
+			case scheme.Type == "oauth2" || scheme.Type == "openIdConnect":
+				req.Auth = "Bearer {{access_token}}"
+			case scheme.Type == "apiKey" && scheme.In == "header":
+				req.Headers[scheme.Name] = "{{api_key}}"
+			case scheme.Type == "apiKey" && scheme.In == "query":
+				if req.Query == nil {
+					req.Query = map[string]string{}
+				}
+				req.Query[scheme.Name] = "{{api_key}}"
+			case scheme.Type == "apiKey" && scheme.In == "cookie":
+				req.Headers["Cookie"] = scheme.Name + "={{api_key}}"
+			default:
+				imp.warnf(req.Name, "security scheme %q (%s) is not supported", schemeName, scheme.Type)
+			}
+		}
+		// Only the first alternative requirement is applied.
+		return
+	}
+}
+
+func (imp *OpenAPIImport) warnf(name, format string, args ...interface{}) {
+	imp.Warnings = append(imp.Warnings, name+": "+fmt.Sprintf(format, args...))
+}
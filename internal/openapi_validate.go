@@ -0,0 +1,447 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SchemaViolation is a single mismatch between a response and the spec.
+// Location is "status", "content-type", "header <Name>", or a JSON pointer
+// into the body such as "#/items/0/id".
+type SchemaViolation struct {
+	Location string
+	Message  string
+}
+
+// OpenAPIValidation is the outcome of validating one response.
+type OpenAPIValidation struct {
+	Operation  string
+	Violations []SchemaViolation
+}
+
+// ValidateResponse finds the operation matching the response's request
+// method and path, then checks the status code, content type, required
+// headers, and JSON body against the documented response.
+func (spec *OpenAPISpec) ValidateResponse(resp *http.Response, body []byte) OpenAPIValidation {
+	method, reqURL := resp.Request.Method, resp.Request.URL
+
+	template, op := spec.findOperation(method, reqURL)
+	if op == nil {
+		return OpenAPIValidation{
+			Operation: method + " " + reqURL.Path,
+			Violations: []SchemaViolation{{
+				Location: "operation",
+				Message:  fmt.Sprintf("no operation in the spec matches %s %s", method, reqURL.Path),
+			}},
+		}
+	}
+
+	v := OpenAPIValidation{Operation: method + " " + template}
+	if op.OperationID != "" {
+		v.Operation += " (" + op.OperationID + ")"
+	}
+
+	r := spec.response(findResponse(op.Responses, resp.StatusCode))
+	if r == nil {
+		v.add("status", "status %d is not documented for this operation", resp.StatusCode)
+		return v
+	}
+
+	for _, name := range sortedKeys(r.Headers) {
+		h := spec.header(r.Headers[name])
+		if h != nil && h.Required && !strings.EqualFold(name, "Content-Type") && resp.Header.Get(name) == "" {
+			v.add("header "+name, "required header is missing")
+		}
+	}
+
+	if len(r.Content) == 0 {
+		return v
+	}
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, media, ok := findMediaType(r.Content, contentType)
+	if !ok {
+		v.add("content-type", "%q is not documented; expected one of %s", contentType, strings.Join(sortedKeys(r.Content), ", "))
+		return v
+	}
+	if media.Schema == nil || !strings.Contains(mediaType, "json") {
+		return v
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		v.add("#", "body is not valid JSON: %v", err)
+		return v
+	}
+	spec.validateSchema(media.Schema, doc, "#", &v.Violations, 0)
+	return v
+}
+
+func (v *OpenAPIValidation) add(location, format string, args ...interface{}) {
+	v.Violations = append(v.Violations, SchemaViolation{Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+// PrintOpenAPIValidation prints the validation outcome, indented by the
+// given prefix, and returns the number of violations.
+func PrintOpenAPIValidation(v OpenAPIValidation, indent string) int {
+	if len(v.Violations) == 0 {
+		fmt.Printf("%s✅ response matches %s\n", indent, v.Operation)
+		return 0
+	}
+	fmt.Printf("%s❌ response does not match %s\n", indent, v.Operation)
+	for _, viol := range v.Violations {
+		fmt.Printf("%s   • %s: %s\n", indent, viol.Location, viol.Message)
+	}
+	return len(v.Violations)
+}
+
+// ── operation lookup ──────────────────────────────────────────────────────────
+
+// findOperation returns the path template and operation matching a request.
+// Server base paths (e.g. /v1) are stripped before matching, and templates
+// with more literal characters win over more generic ones. Remaining ties go
+// to the template with fewer parameters, then the first in lexical order, so
+// the same request always matches the same operation.
+func (spec *OpenAPISpec) findOperation(method string, u *url.URL) (string, *openAPIOperation) {
+	candidates := []string{u.Path}
+	for _, s := range spec.Servers {
+		su, err := url.Parse(spec.serverURL(s))
+		if err != nil || su.Path == "" || su.Path == "/" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(u.Path, su.Path); ok {
+			candidates = append(candidates, rest)
+		}
+	}
+
+	bestTemplate, bestScore, bestParams := "", -1, 0
+	var bestOp *openAPIOperation
+	for _, template := range sortedKeys(spec.Paths) {
+		op := spec.Paths[template].operation(method)
+		if op == nil {
+			continue
+		}
+		re := pathTemplateRegexp(template)
+		for _, p := range candidates {
+			if !re.MatchString(p) {
+				continue
+			}
+			score := len(openAPIPathParam.ReplaceAllString(template, ""))
+			params := len(openAPIPathParam.FindAllStringIndex(template, -1))
+			if score > bestScore || score == bestScore && params < bestParams {
+				bestTemplate, bestScore, bestParams, bestOp = template, score, params, op
+			}
+		}
+	}
+	return bestTemplate, bestOp
+}
+
+// operation returns the operation for an HTTP method, if defined.
+func (p openAPIPathItem) operation(method string) *openAPIOperation {
+	for _, mo := range p.operations() {
+		if mo.method == strings.ToUpper(method) {
+			return mo.op
+		}
+	}
+	return nil
+}
+
+// pathTemplateRegexp compiles an OpenAPI path template such as
+// /users/{id} into an anchored regular expression.
+func pathTemplateRegexp(template string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range openAPIPathParam.FindAllStringIndex(template, -1) {
+		b.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		b.WriteString("[^/]+")
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(template[last:]))
+	b.WriteString("/?$")
+	return regexp.MustCompile(b.String())
+}
+
+// findResponse picks the documented response for a status code: an exact
+// match, then a range such as "2XX", then "default".
+func findResponse(responses map[string]*openAPIResponse, code int) *openAPIResponse {
+	if r, ok := responses[fmt.Sprint(code)]; ok {
+		return r
+	}
+	class := fmt.Sprintf("%dXX", code/100)
+	for k, r := range responses {
+		if strings.EqualFold(k, class) {
+			return r
+		}
+	}
+	return responses["default"]
+}
+
+// findMediaType matches a Content-Type header against documented media
+// types, honouring wildcards like "application/*" and "*/*".
+func findMediaType(content map[string]openAPIMediaType, contentType string) (string, openAPIMediaType, bool) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mt = strings.TrimSpace(strings.ToLower(contentType))
+	}
+	if m, ok := content[mt]; ok {
+		return mt, m, true
+	}
+	if major, _, ok := strings.Cut(mt, "/"); ok {
+		if m, ok := content[major+"/*"]; ok {
+			return mt, m, true
+		}
+	}
+	if m, ok := content["*/*"]; ok {
+		return mt, m, true
+	}
+	return mt, openAPIMediaType{}, false
+}
+
+func (spec *OpenAPISpec) response(r *openAPIResponse) *openAPIResponse {
+	if r == nil {
+		return nil
+	}
+	if name, ok := refName(r.Ref, "responses"); ok {
+		return spec.Components.Responses[name]
+	}
+	return r
+}
+
+func (spec *OpenAPISpec) header(h *openAPIHeader) *openAPIHeader {
+	if h == nil {
+		return nil
+	}
+	if name, ok := refName(h.Ref, "headers"); ok {
+		return spec.Components.Headers[name]
+	}
+	return h
+}
+
+// ── schema validation ─────────────────────────────────────────────────────────
+
+// validateSchema checks a decoded JSON value against a schema, appending a
+// violation with a JSON pointer for every mismatch.
+func (spec *OpenAPISpec) validateSchema(s *openAPISchema, value interface{}, ptr string, out *[]SchemaViolation, depth int) {
+	s = spec.schema(s)
+	if s == nil || depth > 64 {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*out = append(*out, SchemaViolation{Location: ptr, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if s.Nullable || s.Type.has("null") || len(s.Type) == 0 {
+			return
+		}
+		fail("expected %s, got null", strings.Join(s.Type, " or "))
+		return
+	}
+
+	for _, sub := range s.AllOf {
+		spec.validateSchema(sub, value, ptr, out, depth+1)
+	}
+	if len(s.AnyOf) > 0 && spec.countMatches(s.AnyOf, value, depth) == 0 {
+		fail("does not match any of the anyOf schemas")
+	}
+	if len(s.OneOf) > 0 {
+		if n := spec.countMatches(s.OneOf, value, depth); n != 1 {
+			fail("must match exactly one oneOf schema, matched %d", n)
+		}
+	}
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		fail("value %s is not one of the allowed enum values", jsonValueString(value))
+	}
+	if s.Const != nil && !reflect.DeepEqual(s.Const, value) {
+		fail("value %s does not equal const %s", jsonValueString(value), jsonValueString(s.Const))
+	}
+
+	actual := jsonTypeOf(value)
+	if len(s.Type) > 0 && !s.Type.has(actual) && !(actual == "integer" && s.Type.has("number")) {
+		fail("expected %s, got %s", strings.Join(s.Type, " or "), actual)
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*out = append(*out, SchemaViolation{Location: ptr + "/" + escapePointer(name), Message: "missing required property"})
+			}
+		}
+		for _, name := range sortedKeys(v) {
+			child := ptr + "/" + escapePointer(name)
+			if prop, ok := s.Properties[name]; ok {
+				spec.validateSchema(prop, v[name], child, out, depth+1)
+				continue
+			}
+			if ap := s.AdditionalProperties; ap != nil {
+				if !ap.Allowed {
+					*out = append(*out, SchemaViolation{Location: child, Message: "additional property is not allowed"})
+				} else if ap.Schema != nil {
+					spec.validateSchema(ap.Schema, v[name], child, out, depth+1)
+				}
+			}
+		}
+
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("expected at least %d items, got %d", *s.MinItems, len(v))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("expected at most %d items, got %d", *s.MaxItems, len(v))
+		}
+		if s.UniqueItems {
+			for i := range v {
+				for j := i + 1; j < len(v); j++ {
+					if reflect.DeepEqual(v[i], v[j]) {
+						fail("items %d and %d are not unique", i, j)
+					}
+				}
+			}
+		}
+		for i, item := range v {
+			spec.validateSchema(s.Items, item, fmt.Sprintf("%s/%d", ptr, i), out, depth+1)
+		}
+
+	case string:
+		n := len([]rune(v))
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("expected at least %d characters, got %d", *s.MinLength, n)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("expected at most %d characters, got %d", *s.MaxLength, n)
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(v) {
+				fail("%q does not match pattern %q", v, s.Pattern)
+			}
+		}
+		if msg := checkFormat(s.Format, v); msg != "" {
+			fail("%q is not a valid %s", v, msg)
+		}
+
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("%v is less than minimum %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("%v is greater than maximum %v", v, *s.Maximum)
+		}
+		if limit, ok := exclusiveBound(s.ExclusiveMinimum, s.Minimum); ok && v <= limit {
+			fail("%v must be greater than %v", v, limit)
+		}
+		if limit, ok := exclusiveBound(s.ExclusiveMaximum, s.Maximum); ok && v >= limit {
+			fail("%v must be less than %v", v, limit)
+		}
+		if s.MultipleOf != nil && *s.MultipleOf > 0 {
+			if q := v / *s.MultipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+				fail("%v is not a multiple of %v", v, *s.MultipleOf)
+			}
+		}
+	}
+}
+
+// countMatches returns how many of the schemas the value satisfies.
+func (spec *OpenAPISpec) countMatches(schemas []*openAPISchema, value interface{}, depth int) int {
+	n := 0
+	for _, sub := range schemas {
+		var probe []SchemaViolation
+		spec.validateSchema(sub, value, "", &probe, depth+1)
+		if len(probe) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// exclusiveBound interprets exclusiveMinimum/exclusiveMaximum, which is a
+// boolean modifying minimum/maximum in OpenAPI 3.0 and a number in 3.1.
+func exclusiveBound(raw json.RawMessage, inclusive *float64) (float64, bool) {
+	if len(raw) == 0 {
+		return 0, false
+	}
+	var flag bool
+	if json.Unmarshal(raw, &flag) == nil {
+		if flag && inclusive != nil {
+			return *inclusive, true
+		}
+		return 0, false
+	}
+	var n float64
+	if json.Unmarshal(raw, &n) == nil {
+		return n, true
+	}
+	return 0, false
+}
+
+// jsonTypeOf returns the JSON Schema type name of a decoded JSON value.
+func jsonTypeOf(v interface{}) string {
+	switch n := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func enumContains(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// checkFormat validates the common string formats, returning the format
+// name when the value does not conform and "" otherwise.
+func checkFormat(format, v string) string {
+	var ok bool
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		ok = err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", v)
+		ok = err == nil
+	case "uuid":
+		ok = uuidPattern.MatchString(v)
+	case "email":
+		at := strings.LastIndex(v, "@")
+		ok = at > 0 && at < len(v)-1
+	default:
+		return ""
+	}
+	if ok {
+		return ""
+	}
+	return format
+}
+
+// escapePointer escapes a property name for use in a JSON pointer.
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
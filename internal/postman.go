@@ -0,0 +1,426 @@
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// postmanCollection mirrors the parts of the Postman v2.1 collection format
+// that map onto saved requests.
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth,omitempty"`
+	Event    []json.RawMessage `json:"event,omitempty"`
+	Variable []postmanKV       `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	PostmanID string `json:"_postman_id,omitempty"`
+	Name      string `json:"name"`
+	Schema    string `json:"schema"`
+}
+
+// postmanItem is either a folder (Item set) or a request (Request set).
+type postmanItem struct {
+	Name    string            `json:"name"`
+	Item    []postmanItem     `json:"item,omitempty"`
+	Request *postmanRequest   `json:"request,omitempty"`
+	Auth    *postmanAuth      `json:"auth,omitempty"`
+	Event   []json.RawMessage `json:"event,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string       `json:"method"`
+	Header []postmanKV  `json:"header"`
+	Body   *postmanBody `json:"body,omitempty"`
+	URL    postmanURL   `json:"url"`
+	Auth   *postmanAuth `json:"auth,omitempty"`
+}
+
+// UnmarshalJSON accepts both the object form of a request and the shorthand
+// form where the request is just a URL string.
+func (r *postmanRequest) UnmarshalJSON(data []byte) error {
+	var raw string
+	if json.Unmarshal(data, &raw) == nil {
+		*r = postmanRequest{Method: "GET", URL: postmanURL{Raw: raw}}
+		return nil
+	}
+	type plain postmanRequest
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+type postmanURL struct {
+	Raw      string      `json:"raw"`
+	Host     []string    `json:"host,omitempty"`
+	Path     []string    `json:"path,omitempty"`
+	Query    []postmanKV `json:"query,omitempty"`
+	Variable []postmanKV `json:"variable,omitempty"`
+}
+
+// UnmarshalJSON accepts both the object form of a URL and a plain string.
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if json.Unmarshal(data, &raw) == nil {
+		*u = postmanURL{Raw: raw}
+		return nil
+	}
+	type plain postmanURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+// postmanKV is the key/value shape Postman uses for headers, query params,
+// form fields, variables, and auth attributes.
+type postmanKV struct {
+	Key         string      `json:"key"`
+	Value       interface{} `json:"value"`
+	Type        string      `json:"type,omitempty"`
+	Src         interface{} `json:"src,omitempty"`
+	ContentType string      `json:"contentType,omitempty"`
+	Disabled    bool        `json:"disabled,omitempty"`
+	Enabled     *bool       `json:"enabled,omitempty"`
+	Description interface{} `json:"description,omitempty"`
+}
+
+// str returns the value as a string, whatever JSON type Postman stored.
+func (kv postmanKV) str() string {
+	switch v := kv.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return jsonValueString(v)
+	}
+}
+
+type postmanBody struct {
+	Mode       string          `json:"mode"`
+	Raw        string          `json:"raw,omitempty"`
+	URLEncoded []postmanKV     `json:"urlencoded,omitempty"`
+	FormData   []postmanKV     `json:"formdata,omitempty"`
+	GraphQL    *postmanGraphQL `json:"graphql,omitempty"`
+	Options    *postmanOptions `json:"options,omitempty"`
+}
+
+type postmanGraphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+}
+
+type postmanOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type postmanAuth struct {
+	Type   string      `json:"type"`
+	Bearer []postmanKV `json:"bearer,omitempty"`
+	Basic  []postmanKV `json:"basic,omitempty"`
+	APIKey []postmanKV `json:"apikey,omitempty"`
+}
+
+// attr looks up an auth attribute such as "token" or "username".
+func attr(kvs []postmanKV, key string) string {
+	for _, kv := range kvs {
+		if kv.Key == key {
+			return kv.str()
+		}
+	}
+	return ""
+}
+
+// postmanEnvironment mirrors a Postman environment export.
+type postmanEnvironment struct {
+	Name   string      `json:"name"`
+	Values []postmanKV `json:"values"`
+}
+
+// PostmanImport is the result of converting a Postman collection.
+type PostmanImport struct {
+	Name      string
+	Requests  []SavedRequest
+	Variables Env
+	Warnings  []string
+}
+
+// rawContentTypes maps Postman raw body languages to the Content-Type
+// Postman would send. JSON is omitted since SendRequest defaults to it.
+var rawContentTypes = map[string]string{
+	"text":       "text/plain",
+	"xml":        "application/xml",
+	"html":       "text/html",
+	"javascript": "application/javascript",
+}
+
+// postmanPathVar matches Postman path variables such as :id in a URL.
+var postmanPathVar = regexp.MustCompile(`/:([A-Za-z_][A-Za-z0-9_]*)`)
+
+// ImportPostmanCollection converts a Postman v2.1 collection export into
+// saved requests. Folder names become a "/"-separated name prefix and tags.
+// Anything that cannot be represented is skipped and reported in Warnings.
+func ImportPostmanCollection(data []byte) (PostmanImport, error) {
+	var pc postmanCollection
+	if err := json.Unmarshal(data, &pc); err != nil {
+		return PostmanImport{}, fmt.Errorf("invalid Postman collection: %w", err)
+	}
+	if pc.Info.Schema == "" || pc.Item == nil {
+		return PostmanImport{}, fmt.Errorf("not a Postman collection: missing info.schema or item")
+	}
+
+	imp := PostmanImport{Name: pc.Info.Name, Variables: Env{}}
+	if !strings.Contains(pc.Info.Schema, "v2.1") {
+		imp.Warnings = append(imp.Warnings, fmt.Sprintf("collection schema %q is not v2.1; importing on a best-effort basis", pc.Info.Schema))
+	}
+	if len(pc.Event) > 0 {
+		imp.Warnings = append(imp.Warnings, "collection-level scripts are not supported and were skipped")
+	}
+	for _, v := range pc.Variable {
+		if v.Disabled {
+			continue
+		}
+		imp.Variables[v.Key] = v.str()
+	}
+
+	imp.walk(pc.Item, nil, pc.Auth)
+	return imp, nil
+}
+
+// walk converts a list of items, recursing into folders.
+func (imp *PostmanImport) walk(items []postmanItem, folders []string, auth *postmanAuth) {
+	for _, it := range items {
+		itemAuth := auth
+		if it.Auth != nil && it.Auth.Type != "inherit" {
+			itemAuth = it.Auth
+		}
+
+		path := append(append([]string{}, folders...), it.Name)
+		name := strings.Join(path, "/")
+
+		if it.Request == nil {
+			if len(it.Event) > 0 {
+				imp.warnf(name, "folder scripts are not supported and were skipped")
+			}
+			imp.walk(it.Item, path, itemAuth)
+			continue
+		}
+
+		if it.Request.Auth != nil && it.Request.Auth.Type != "inherit" {
+			itemAuth = it.Request.Auth
+		}
+		req := imp.convertRequest(name, it.Request, itemAuth)
+		if len(folders) > 0 {
+			req.Tags = append([]string{}, folders...)
+		}
+		if len(it.Event) > 0 {
+			imp.warnf(name, "pre-request and test scripts are not supported and were skipped")
+		}
+		imp.Requests = append(imp.Requests, req)
+	}
+}
+
+// convertRequest translates a single Postman request into a SavedRequest.
+func (imp *PostmanImport) convertRequest(name string, pr *postmanRequest, auth *postmanAuth) SavedRequest {
+	req := SavedRequest{
+		Name:    name,
+		Method:  strings.ToUpper(pr.Method),
+		URL:     postmanRawURL(pr.URL),
+		Headers: map[string]string{},
+	}
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+
+	for _, h := range pr.Header {
+		if h.Disabled {
+			continue
+		}
+		if _, dup := req.Headers[h.Key]; dup {
+			imp.warnf(name, "duplicate header %q kept only the last value", h.Key)
+		}
+		req.Headers[h.Key] = h.str()
+	}
+
+	if pr.Body != nil {
+		imp.convertBody(&req, pr.Body)
+	}
+	if auth != nil {
+		imp.convertAuth(&req, auth)
+	}
+	if len(req.Headers) == 0 {
+		req.Headers = nil
+	}
+	return req
+}
+
+// convertBody translates a Postman body into the request body and headers.
+func (imp *PostmanImport) convertBody(req *SavedRequest, b *postmanBody) {
+	switch b.Mode {
+	case "", "none":
+	case "raw":
+		req.Body = b.Raw
+		if b.Options != nil {
+			if ct, ok := rawContentTypes[b.Options.Raw.Language]; ok && !hasHeader(req.Headers, "Content-Type") {
+				req.Headers["Content-Type"] = ct
+			}
+		}
+	case "urlencoded":
+		var parts []string
+		for _, kv := range b.URLEncoded {
+			if kv.Disabled {
+				continue
+			}
+			parts = append(parts, escapeKeepingVars(kv.Key)+"="+escapeKeepingVars(kv.str()))
+		}
+		req.Body = strings.Join(parts, "&")
+		if !hasHeader(req.Headers, "Content-Type") {
+			req.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+	case "graphql":
+		if b.GraphQL == nil {
+			return
+		}
+		payload := map[string]interface{}{"query": b.GraphQL.Query}
+		if strings.TrimSpace(b.GraphQL.Variables) != "" {
+			payload["variables"] = json.RawMessage(b.GraphQL.Variables)
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			imp.warnf(req.Name, "GraphQL variables are not valid JSON; body skipped")
+			return
+		}
+		req.Body = string(data)
+	case "formdata":
+		for _, kv := range b.FormData {
+			if kv.Disabled {
+				continue
+			}
+			if kv.Type != "file" {
+				req.Form = append(req.Form, FormPart{Name: kv.Key, Value: kv.str(), ContentType: kv.ContentType})
+				continue
+			}
+			// src is a path, a list of paths, or missing when the file was
+			// never chosen in Postman.
+			var srcs []string
+			switch src := kv.Src.(type) {
+			case string:
+				srcs = []string{src}
+			case []interface{}:
+				for _, s := range src {
+					if str, ok := s.(string); ok {
+						srcs = append(srcs, str)
+					}
+				}
+			}
+			if len(srcs) == 0 {
+				imp.warnf(req.Name, "form file field %q has no file selected; using {{%s_file}}", kv.Key, kv.Key)
+				srcs = []string{"{{" + kv.Key + "_file}}"}
+			}
+			for _, src := range srcs {
+				req.Form = append(req.Form, FormPart{Name: kv.Key, File: src, ContentType: kv.ContentType})
+			}
+		}
+	default:
+		imp.warnf(req.Name, "%q bodies are not supported; body skipped", b.Mode)
+	}
+}
+
+// convertAuth translates Postman bearer, basic, and API key auth.
+func (imp *PostmanImport) convertAuth(req *SavedRequest, a *postmanAuth) {
+	switch a.Type {
+	case "", "noauth", "inherit":
+	case "bearer":
+		req.Auth = "Bearer " + attr(a.Bearer, "token")
+	case "basic":
+		user, pass := attr(a.Basic, "username"), attr(a.Basic, "password")
+		if strings.Contains(user+pass, "{{") {
+			// Kept unencoded so the variables are filled in before encoding.
+			req.Auth = "Basic " + user + ":" + pass
+			return
+		}
+		req.Auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+	case "apikey":
+		key, value := attr(a.APIKey, "key"), attr(a.APIKey, "value")
+		if attr(a.APIKey, "in") == "query" {
+			sep := "?"
+			if strings.Contains(req.URL, "?") {
+				sep = "&"
+			}
+			req.URL += sep + escapeKeepingVars(key) + "=" + escapeKeepingVars(value)
+			return
+		}
+		req.Headers[key] = value
+	default:
+		imp.warnf(req.Name, "%q auth is not supported; auth skipped", a.Type)
+	}
+}
+
+func (imp *PostmanImport) warnf(name, format string, args ...interface{}) {
+	imp.Warnings = append(imp.Warnings, name+": "+fmt.Sprintf(format, args...))
+}
+
+// postmanRawURL returns the request URL with Postman path variables (:id)
+// replaced by their values, or by {{id}} placeholders when no value is set.
+func postmanRawURL(u postmanURL) string {
+	raw := u.Raw
+	if raw == "" && len(u.Host) > 0 {
+		raw = strings.Join(u.Host, ".") + "/" + strings.Join(u.Path, "/")
+	}
+	values := map[string]string{}
+	for _, v := range u.Variable {
+		values[v.Key] = v.str()
+	}
+	return postmanPathVar.ReplaceAllStringFunc(raw, func(m string) string {
+		key := m[2:]
+		if v := values[key]; v != "" {
+			return "/" + v
+		}
+		return "/{{" + key + "}}"
+	})
+}
+
+// escapeKeepingVars query-escapes s while leaving {{variable}} placeholders
+// intact so they are still interpolated at send time.
+func escapeKeepingVars(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range varPattern.FindAllStringIndex(s, -1) {
+		b.WriteString(url.QueryEscape(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(url.QueryEscape(s[last:]))
+	return b.String()
+}
+
+// ImportPostmanEnvironment converts a Postman environment export into the
+// flat key/value format read by LoadEnv. Disabled variables are skipped and
+// reported in the returned warnings.
+func ImportPostmanEnvironment(data []byte) (Env, []string, error) {
+	var pe postmanEnvironment
+	if err := json.Unmarshal(data, &pe); err != nil {
+		return nil, nil, fmt.Errorf("invalid Postman environment: %w", err)
+	}
+	if pe.Values == nil {
+		return nil, nil, fmt.Errorf("not a Postman environment: missing values")
+	}
+
+	env := Env{}
+	var warnings []string
+	for _, v := range pe.Values {
+		if v.Key == "" {
+			continue
+		}
+		if v.Disabled || (v.Enabled != nil && !*v.Enabled) {
+			warnings = append(warnings, fmt.Sprintf("%s: disabled variable skipped", v.Key))
+			continue
+		}
+		env[v.Key] = v.str()
+	}
+	return env, warnings, nil
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseQueryParams parses a list of -q/--query "name=value" values. A name
+// given more than once keeps every value, in order.
+func ParseQueryParams(specs []string) (url.Values, error) {
+	query := url.Values{}
+	for _, s := range specs {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid query parameter %q: expected name=value", s)
+		}
+		query.Add(name, value)
+	}
+	return query, nil
+}
+
+// WithQuery returns rawURL with query merged into its query string.
+// Parameters already in the URL are kept as written unless query sets the
+// same name, in which case they are replaced. Added names and values are
+// percent-encoded, leaving {{variable}} placeholders intact, and the
+// parameters are appended in name order.
+func WithQuery(rawURL string, query url.Values) string {
+	if len(query) == 0 {
+		return rawURL
+	}
+
+	base, fragment, hasFragment := strings.Cut(rawURL, "#")
+	base, existing, _ := strings.Cut(base, "?")
+
+	var pairs []string
+	for _, pair := range strings.Split(existing, "&") {
+		if pair == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if _, replaced := query[name]; replaced {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	for _, name := range sortedKeys(query) {
+		for _, value := range query[name] {
+			pairs = append(pairs, escapeKeepingVars(name)+"="+escapeKeepingVars(value))
+		}
+	}
+
+	s := base + "?" + strings.Join(pairs, "&")
+	if hasFragment {
+		s += "#" + fragment
+	}
+	return s
+}
+
+// queryValues converts a saved request's query map into url.Values.
+func queryValues(query map[string]string) url.Values {
+	values := url.Values{}
+	for k, v := range query {
+		values.Set(k, v)
+	}
+	return values
+}
+
+// FullURL returns the request URL with its saved query parameters merged
+// in, placeholders and all.
+func (r SavedRequest) FullURL() string {
+	return WithQuery(r.URL, queryValues(r.Query))
+}
@@ -4,10 +4,12 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"os"
 	"strings"
 	"time"
@@ -19,15 +21,52 @@ type RequestOptions struct {
 	URL     string
 	Headers map[string]string
 	Body    string
+	Form    []FormPart // multipart/form-data fields; used instead of Body
 	Auth    string
 	Timeout time.Duration
+	TLS     TLSOptions
+	Proxy   string // proxy URL; empty means use HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+
+	Timeouts Timeouts // per-phase limits within Timeout
+
+	NoFollow     bool // return 3xx responses instead of following them
+	MaxRedirects int  // 0 means 10, negative means no limit
+	KeepAuth     bool // keep the Authorization header when a redirect changes host
+
+	Retry RetryPolicy
+
+	// Jar, when set, supplies cookies for the request and stores any the
+	// server sets.
+	Jar *CookieJar
+
+	// Trace, when set, is filled in with the headers sent and the
+	// connection timing breakdown.
+	Trace *RequestTrace
 }
 
 // SendRequest dispatches an HTTP request and returns the response, body bytes, duration, and any error.
 // The duration includes the full round-trip including reading the response body.
+// Failed attempts are retried according to opts.Retry, reporting each one; the
+// result is that of the last attempt.
 func SendRequest(opts RequestOptions) (*http.Response, []byte, time.Duration, error) {
+	resp, body, duration, err := sendOnce(opts)
+	for retry := 1; retry <= opts.Retry.Max && opts.Retry.retryable(resp, err); retry++ {
+		delay := opts.Retry.backoff(retry, resp)
+		fmt.Printf("↻ Attempt %d/%d: %s — retrying in %v\n",
+			retry, opts.Retry.Max+1, attemptOutcome(resp, err), delay.Round(time.Millisecond))
+		time.Sleep(delay)
+		resp, body, duration, err = sendOnce(opts)
+	}
+	return resp, body, duration, err
+}
+
+// sendOnce makes a single attempt at a request.
+func sendOnce(opts RequestOptions) (*http.Response, []byte, time.Duration, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
 	defer cancel()
+	if opts.Trace != nil {
+		ctx = httptrace.WithClientTrace(ctx, opts.Trace.clientTrace())
+	}
 
 	var bodyReader io.Reader
 	if opts.Body != "" {
@@ -50,16 +89,35 @@ func SendRequest(opts RequestOptions) (*http.Response, []byte, time.Duration, er
 	}
 
 	if opts.Auth != "" {
-		if strings.HasPrefix(opts.Auth, "Bearer ") || strings.HasPrefix(opts.Auth, "Basic ") {
-			req.Header.Set("Authorization", opts.Auth)
-		} else {
-			req.Header.Set("Authorization", "Bearer "+opts.Auth)
+		req.Header.Set("Authorization", AuthorizationValue(opts.Auth))
+	}
+
+	// Multipart bodies are streamed, and carry their boundary in the
+	// Content-Type, so they override any Content-Type header given.
+	if len(opts.Form) > 0 {
+		body, contentType, length, err := multipartBody(opts.Form)
+		if err != nil {
+			return nil, nil, 0, err
 		}
+		req.Body = body
+		req.ContentLength = length
+		req.Header.Set("Content-Type", contentType)
 	}
 
-	client := &http.Client{}
+	transport, err := transportFor(transportKey{tls: opts.TLS, proxy: opts.Proxy, timeouts: opts.Timeouts})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	client := &http.Client{Transport: transport, CheckRedirect: redirectPolicy(opts)}
+	if opts.Jar != nil {
+		client.Jar = opts.Jar
+	}
 
 	start := time.Now()
+	if opts.Trace != nil {
+		opts.Trace.begin(start)
+		defer opts.Trace.finish()
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, nil, time.Since(start), err
@@ -77,6 +135,83 @@ func SendRequest(opts RequestOptions) (*http.Response, []byte, time.Duration, er
 	return resp, body, duration, nil
 }
 
+// redirectPolicy returns a CheckRedirect function that applies the redirect
+// options and records each hop in opts.Trace.
+func redirectPolicy(opts RequestOptions) func(*http.Request, []*http.Request) error {
+	maxRedirects := opts.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = 10
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if opts.NoFollow {
+			return http.ErrUseLastResponse
+		}
+		if maxRedirects > 0 && len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		if opts.Trace != nil {
+			opts.Trace.addRedirect(via[len(via)-1], req.Response)
+		}
+
+		// Go keeps credentials when redirecting to a subdomain; be stricter
+		// and drop them on any change of host unless asked to keep them.
+		auth := via[0].Header.Get("Authorization")
+		switch {
+		case auth == "":
+		case opts.KeepAuth:
+			req.Header.Set("Authorization", auth)
+		case req.URL.Host != via[0].URL.Host:
+			req.Header.Del("Authorization")
+		}
+		return nil
+	}
+}
+
+// AuthorizationValue normalizes an --auth value into an Authorization header
+// value. Values without a "Bearer " or "Basic " scheme are treated as bearer
+// tokens. "Basic user:pass" is base64-encoded here, so credentials holding
+// {{variables}} can be stored unencoded and interpolated first.
+func AuthorizationValue(auth string) string {
+	if creds, ok := plainBasicCredentials(auth); ok {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds))
+	}
+	if strings.HasPrefix(auth, "Bearer ") || strings.HasPrefix(auth, "Basic ") {
+		return auth
+	}
+	return "Bearer " + auth
+}
+
+// plainBasicCredentials returns the user:pass of an unencoded "Basic
+// user:pass" auth value. Base64 never contains a colon, so the two forms
+// can't be confused.
+func plainBasicCredentials(auth string) (string, bool) {
+	creds, ok := strings.CutPrefix(auth, "Basic ")
+	if !ok || !strings.Contains(creds, ":") {
+		return "", false
+	}
+	return creds, true
+}
+
+// hasHeader reports whether headers contains key, ignoring case.
+func hasHeader(headers map[string]string, key string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerValue returns the value of key in headers, ignoring case.
+func headerValue(headers map[string]string, key string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
 // ReadBodyInteractive reads a single line of JSON from stdin with a user prompt.
 // The user types their JSON body and presses Enter — no Ctrl+D/Z needed.
 func ReadBodyInteractive() (string, error) {
@@ -100,11 +235,43 @@ func ValidateJSON(s string) error {
 	return nil
 }
 
-// PrintResponse pretty-prints an HTTP response body to stdout.
-func PrintResponse(resp *http.Response, body []byte, duration time.Duration) {
+// ValidMethod reports whether m is a valid HTTP method token (RFC 9110),
+// which covers extension methods such as WebDAV's PROPFIND or MKCOL.
+func ValidMethod(m string) bool {
+	if m == "" {
+		return false
+	}
+	for _, c := range m {
+		if c > 0x7e || c <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// PrintResponse pretty-prints an HTTP response body to stdout. HEAD
+// responses show their headers instead of a body, and OPTIONS responses
+// lead with the allowed methods and any CORS headers. method is the method
+// originally sent, since a followed redirect may have turned it into a GET.
+func PrintResponse(method string, resp *http.Response, body []byte, duration time.Duration) {
 	fmt.Printf("Status:   %s\n", resp.Status)
+	if loc := resp.Header.Get("Location"); loc != "" {
+		fmt.Printf("Location: %s\n", loc)
+	}
 	fmt.Printf("Duration: %v\n", duration)
 
+	switch strings.ToUpper(method) {
+	case http.MethodHead:
+		fmt.Println("Headers:")
+		printHeaders(resp.Header, func(string) bool { return true })
+		return
+	case http.MethodOptions:
+		printOptions(resp.Header)
+		if len(body) == 0 {
+			return
+		}
+	}
+
 	var pretty bytes.Buffer
 	if json.Indent(&pretty, body, "", "  ") == nil {
 		fmt.Println("Response (JSON):")
@@ -114,3 +281,36 @@ func PrintResponse(resp *http.Response, body []byte, duration time.Duration) {
 		fmt.Println(string(body))
 	}
 }
+
+// printOptions prints the Allow header and any CORS headers of an OPTIONS
+// response.
+func printOptions(h http.Header) {
+	allow := h.Values("Allow")
+	if len(allow) == 0 {
+		fmt.Println("Allow:    (not sent)")
+	} else {
+		fmt.Printf("Allow:    %s\n", strings.Join(allow, ", "))
+	}
+
+	isCORS := func(k string) bool { return strings.HasPrefix(k, "Access-Control-") || k == "Vary" }
+	for k := range h {
+		if strings.HasPrefix(k, "Access-Control-") {
+			fmt.Println("CORS:")
+			printHeaders(h, isCORS)
+			return
+		}
+	}
+	fmt.Println("CORS:     (no Access-Control-* headers)")
+}
+
+// printHeaders prints the headers selected by keep, sorted by name.
+func printHeaders(h http.Header, keep func(string) bool) {
+	for _, k := range sortedKeys(h) {
+		if !keep(k) {
+			continue
+		}
+		for _, v := range h[k] {
+			fmt.Printf("  %s: %s\n", k, v)
+		}
+	}
+}
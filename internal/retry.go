@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetryOn lists the conditions retried when none are given.
+const DefaultRetryOn = "429,502,503,504,connection-errors"
+
+const (
+	defaultRetryDelay = time.Second
+	maxRetryDelay     = 30 * time.Second
+	maxRetryAfter     = 2 * time.Minute
+)
+
+// RetryPolicy controls how SendRequest retries transient failures.
+//
+// On lists the conditions that trigger a retry: status codes ("503"),
+// status classes ("5xx"), and "connection-errors" for requests that got no
+// response at all (refused connections, resets, timeouts).
+type RetryPolicy struct {
+	Max   int           `json:"max,omitempty"`
+	Delay time.Duration `json:"delay_ns,omitempty"`
+	On    []string      `json:"on,omitempty"`
+}
+
+// ParseRetryOn parses a comma-separated --retry-on list.
+func ParseRetryOn(s string) ([]string, error) {
+	var on []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		switch {
+		case part == "":
+			continue
+		case part == "connection-errors":
+		case len(part) == 3 && strings.HasSuffix(part, "xx") && part[0] >= '1' && part[0] <= '5':
+		default:
+			code, err := strconv.Atoi(part)
+			if err != nil || code < 100 || code > 599 {
+				return nil, fmt.Errorf("invalid --retry-on condition %q (want a status code, a class like 5xx, or connection-errors)", part)
+			}
+		}
+		on = append(on, part)
+	}
+	return on, nil
+}
+
+// retryable reports whether a response or error matches the policy.
+func (p RetryPolicy) retryable(resp *http.Response, err error) bool {
+	on := p.On
+	if len(on) == 0 {
+		on, _ = ParseRetryOn(DefaultRetryOn)
+	}
+	for _, cond := range on {
+		switch {
+		case cond == "connection-errors":
+			if err != nil && resp == nil {
+				return true
+			}
+		case resp == nil:
+		case strings.HasSuffix(cond, "xx"):
+			if strconv.Itoa(resp.StatusCode/100) == cond[:1] {
+				return true
+			}
+		case cond == strconv.Itoa(resp.StatusCode):
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns how long to wait before the given retry (1-based). A
+// Retry-After header on the response takes precedence; otherwise the delay
+// doubles each attempt, with random jitter so that many clients do not
+// retry in lockstep.
+func (p RetryPolicy) backoff(retry int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(d, maxRetryAfter)
+		}
+	}
+
+	base := p.Delay
+	if base <= 0 {
+		base = defaultRetryDelay
+	}
+	d := base << (retry - 1)
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	// Equal jitter: half fixed, half random.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date.
+func retryAfter(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// attemptOutcome describes a failed attempt for the retry report.
+func attemptOutcome(resp *http.Response, err error) string {
+	if resp != nil {
+		return resp.Status
+	}
+	return err.Error()
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RunResult records the outcome of a single request within a collection run.
+type RunResult struct {
+	Name     string
+	Method   string
+	URL      string
+	Status   string
+	Duration time.Duration
+	Err      error
+	Passed   bool
+
+	Assertions []AssertionResult
+	Captures   []CaptureResult
+	OpenAPI    *OpenAPIValidation
+}
+
+// NewRunResult builds a RunResult from the values returned by SendRequest,
+// applying the request's captures to env. A request passes when it completes
+// without a transport error, every capture succeeds, and either all of its
+// assertions hold or, if it has none, the server responds with a non-error
+// (< 400) status code.
+func NewRunResult(req SavedRequest, env Env, url string, resp *http.Response, body []byte, duration time.Duration, err error) RunResult {
+	r := RunResult{
+		Name:     req.Name,
+		Method:   req.Method,
+		URL:      url,
+		Duration: duration,
+		Err:      err,
+	}
+	if err == nil && resp != nil {
+		r.Status = resp.Status
+		r.Passed = resp.StatusCode < 400
+		if len(req.Assertions) > 0 {
+			r.Assertions = EvaluateAssertions(req.Assertions, resp, body, duration)
+			r.Passed = true
+			for _, a := range r.Assertions {
+				if !a.Passed {
+					r.Passed = false
+				}
+			}
+		}
+		r.Captures = ApplyCaptures(env, req.Captures, resp, body)
+		for _, c := range r.Captures {
+			if c.Err != nil {
+				r.Passed = false
+			}
+		}
+	}
+	return r
+}
+
+// ValidateOpenAPI checks the response against an OpenAPI spec, failing the
+// result if it does not conform.
+func (r *RunResult) ValidateOpenAPI(spec *OpenAPISpec, resp *http.Response, body []byte) {
+	v := spec.ValidateResponse(resp, body)
+	r.OpenAPI = &v
+	if len(v.Violations) > 0 {
+		r.Passed = false
+	}
+}
+
+// PrintRunResult prints a one-line status for a finished request.
+func PrintRunResult(r RunResult) {
+	mark := "✅ PASS"
+	if !r.Passed {
+		mark = "❌ FAIL"
+	}
+	status := r.Status
+	if r.Err != nil {
+		status = "error: " + r.Err.Error()
+	}
+	fmt.Printf("%s  %-20s  %-7s  %-26s  %v\n", mark, r.Name, r.Method, status, r.Duration.Round(time.Millisecond))
+	PrintAssertionResults(r.Assertions, "         ")
+	PrintCaptureResults(r.Captures, "         ")
+	if r.OpenAPI != nil && len(r.OpenAPI.Violations) > 0 {
+		PrintOpenAPIValidation(*r.OpenAPI, "         ")
+	}
+}
+
+// PrintRunSummary prints a summary table for a collection run.
+func PrintRunSummary(results []RunResult, total time.Duration) {
+	passed, checks, checksFailed := 0, 0, 0
+	for _, r := range results {
+		if r.Passed {
+			passed++
+		}
+		for _, a := range r.Assertions {
+			checks++
+			if !a.Passed {
+				checksFailed++
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("═══════════════════ COLLECTION RUN SUMMARY ═══════════════════")
+	fmt.Printf("  %-20s  %-7s  %-26s  %-10s  %s\n", "NAME", "METHOD", "STATUS", "DURATION", "RESULT")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	for _, r := range results {
+		status := r.Status
+		if r.Err != nil {
+			status = "error"
+		}
+		result := "PASS"
+		if !r.Passed {
+			result = "FAIL"
+		}
+		fmt.Printf("  %-20s  %-7s  %-26s  %-10v  %s\n", r.Name, r.Method, status, r.Duration.Round(time.Millisecond), result)
+	}
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("  Requests:     %d\n", len(results))
+	fmt.Printf("  Passed:       %d\n", passed)
+	fmt.Printf("  Failed:       %d\n", len(results)-passed)
+	if checks > 0 {
+		fmt.Printf("  Assertions:   %d passed, %d failed\n", checks-checksFailed, checksFailed)
+	}
+	fmt.Printf("  Total Time:   %v\n", total.Round(time.Millisecond))
+	fmt.Println("══════════════════════════════════════════════════════════════")
+}
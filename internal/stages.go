@@ -0,0 +1,203 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Stage is one step of a staged stress profile. Over Duration the load moves
+// linearly from the previous stage's target (zero before the first stage)
+// to Target, which is a worker count, or an arrival rate in requests per
+// second when Rate is set.
+type Stage struct {
+	Name     string
+	Duration time.Duration
+	Target   float64
+	Rate     bool
+}
+
+// String renders the stage the way ParseStages accepts it.
+func (s Stage) String() string {
+	if s.Rate {
+		return s.Duration.String() + ":" + FormatRate(s.Target)
+	}
+	return s.Duration.String() + ":" + strconv.FormatFloat(s.Target, 'f', -1, 64)
+}
+
+// ParseStages parses a --stages value: either a comma-separated list of
+// duration:target pairs such as "30s:10,2m:100,30s:0", or the path of a
+// YAML profile ending in .yaml or .yml:
+//
+//	stages:
+//	  - name: warm-up
+//	    duration: 30s
+//	    target: 10       # workers, or "50/s" for an arrival rate
+//	  - duration: 2m
+//	    target: 100
+//
+// Targets written as rates ("50/s", "3000/m") ramp the arrival rate instead
+// of the number of workers; every stage must use the same kind.
+func ParseStages(spec string) ([]Stage, error) {
+	if strings.HasSuffix(spec, ".yaml") || strings.HasSuffix(spec, ".yml") {
+		return loadStagesFile(spec)
+	}
+
+	var stages []Stage
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		dur, target, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid stage %q: expected duration:target (e.g. 30s:10)", part)
+		}
+		d, err := time.ParseDuration(dur)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stage %q: %w", part, err)
+		}
+		stage, err := newStage("", d, target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stage %q: %w", part, err)
+		}
+		stages = append(stages, stage)
+	}
+	return stages, checkStages(stages)
+}
+
+// stagesFile is the YAML form of a staged profile.
+type stagesFile struct {
+	Stages []struct {
+		Name     string        `yaml:"name"`
+		Duration time.Duration `yaml:"duration"`
+		Target   string        `yaml:"target"`
+	} `yaml:"stages"`
+}
+
+func loadStagesFile(path string) ([]Stage, error) {
+	if err := CheckLocalFileRead("stages file "+path, path); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read stages file: %w", err)
+	}
+	var f stagesFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid stages file %s: %w", path, err)
+	}
+
+	stages := make([]Stage, 0, len(f.Stages))
+	for i, s := range f.Stages {
+		stage, err := newStage(s.Name, s.Duration, s.Target)
+		if err != nil {
+			return nil, fmt.Errorf("%s: stage %d: %w", path, i+1, err)
+		}
+		stages = append(stages, stage)
+	}
+	return stages, checkStages(stages)
+}
+
+// newStage builds a stage from a target written as a worker count or a
+// rate.
+func newStage(name string, d time.Duration, target string) (Stage, error) {
+	if d <= 0 {
+		return Stage{}, fmt.Errorf("duration must be positive")
+	}
+	target = strings.TrimSpace(target)
+	if strings.Contains(target, "/") {
+		rate, err := parseRate(target)
+		if err != nil {
+			return Stage{}, err
+		}
+		return Stage{Name: name, Duration: d, Target: rate, Rate: true}, nil
+	}
+	workers, err := strconv.Atoi(target)
+	if err != nil || workers < 0 {
+		return Stage{}, fmt.Errorf("invalid target %q (want a worker count like 10 or a rate like 50/s)", target)
+	}
+	return Stage{Name: name, Duration: d, Target: float64(workers)}, nil
+}
+
+func checkStages(stages []Stage) error {
+	if len(stages) == 0 {
+		return fmt.Errorf("no stages given")
+	}
+	for _, s := range stages[1:] {
+		if s.Rate != stages[0].Rate {
+			return fmt.Errorf("stages mix worker counts and rates; use one kind throughout")
+		}
+	}
+	return nil
+}
+
+// StagesDuration returns the total length of a staged profile.
+func StagesDuration(stages []Stage) time.Duration {
+	var total time.Duration
+	for _, s := range stages {
+		total += s.Duration
+	}
+	return total
+}
+
+// stageIndex returns the stage running at the given offset from the start
+// of the test.
+func stageIndex(stages []Stage, at time.Duration) int {
+	for i, s := range stages {
+		if at < s.Duration {
+			return i
+		}
+		at -= s.Duration
+	}
+	return len(stages) - 1
+}
+
+// stageTarget returns the interpolated target at the given offset.
+func stageTarget(stages []Stage, at time.Duration) float64 {
+	from := 0.0
+	for _, s := range stages {
+		if at < s.Duration {
+			return from + (s.Target-from)*float64(at)/float64(s.Duration)
+		}
+		at -= s.Duration
+		from = s.Target
+	}
+	return from
+}
+
+// stageSendOffset returns when the i-th request (0-based) of a staged
+// arrival-rate profile is due, as an offset from the start of the test. It
+// inverts the cumulative request count, which is quadratic within a stage
+// because the rate changes linearly. ok is false once the profile ends.
+func stageSendOffset(stages []Stage, i int) (at time.Duration, ok bool) {
+	k := float64(i) // requests still to place before the i-th
+	from := 0.0
+	var elapsed time.Duration
+	for _, s := range stages {
+		d := s.Duration.Seconds()
+		// Requests sent during this stage: the area under the rate ramp.
+		if n := (from + s.Target) / 2 * d; k >= n {
+			k -= n
+		} else {
+			// Solve a·t² + b·t = k for t within the stage.
+			a := (s.Target - from) / (2 * d)
+			b := from
+			var t float64
+			if a == 0 {
+				t = k / b
+			} else {
+				t = (-b + math.Sqrt(b*b+4*a*k)) / (2 * a)
+			}
+			return elapsed + time.Duration(t*float64(time.Second)), true
+		}
+		elapsed += s.Duration
+		from = s.Target
+	}
+	return 0, false
+}
@@ -3,12 +3,15 @@ package internal
 import (
 	"context"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
-	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
 // StressOptions defines the parameters for a stress test run.
@@ -20,34 +23,251 @@ type StressOptions struct {
 	Auth        string
 	Concurrency int
 	Duration    time.Duration
-	MaxRequests int // 0 means unlimited (use Duration instead)
-	Timeout     time.Duration
+	MaxRequests int           // 0 means unlimited (use Duration instead)
+	Rate        float64       // requests per second; 0 runs a closed loop of Concurrency workers
+	MaxInFlight int           // cap on outstanding requests in an open loop
+	Stages      []Stage       // ramps workers or rate instead of Concurrency/Rate; Duration is their total
+	Precision   int           // significant digits kept by latency histograms; 0 means DefaultHistogramPrecision
+	Timeout     time.Duration // per request; 0 means none
+	Timeouts    Timeouts
+	TLS         TLSOptions
+	Proxy       string
+
+	// Progress, if set, is called every second while the test runs.
+	Progress func(StressProgress)
 }
 
-// StressResult holds the aggregated results of a stress test.
-type StressResult struct {
+// openLoop reports whether requests are scheduled by arrival rate rather
+// than sent back-to-back by workers.
+func (o StressOptions) openLoop() bool {
+	if len(o.Stages) > 0 {
+		return o.Stages[0].Rate
+	}
+	return o.Rate > 0
+}
+
+// precision returns the precision of the overall latency histograms.
+func (o StressOptions) precision() int {
+	if o.Precision == 0 {
+		return DefaultHistogramPrecision
+	}
+	return o.Precision
+}
+
+// breakdownPrecision returns the precision of the per-status and per-stage
+// histograms. It never goes above the default, so a high Precision costs
+// memory only for the two overall histograms rather than for every status
+// code and stage.
+func (o StressOptions) breakdownPrecision() int {
+	return min(o.precision(), DefaultHistogramPrecision)
+}
+
+// StressStats are the counts and latencies of a stress test or one stage of
+// it.
+type StressStats struct {
 	TotalRequests int
 	Successes     int
 	Failures      int
-	Latencies     []time.Duration
-	Errors        []string
+	Dropped       int        // open loop: sends skipped because MaxInFlight were outstanding
+	Late          int        // open loop: sends that went out noticeably after they were due
+	Latencies     *Histogram // successful requests
+	Failed        *Histogram // failed requests, until the error or error status
+}
+
+func newStressStats(precision int) StressStats {
+	return StressStats{Latencies: mustHistogram(precision), Failed: mustHistogram(precision)}
+}
+
+// record adds one sample to the stats.
+func (s *StressStats) record(r stressSample) {
+	if r.dropped {
+		s.Dropped++
+		return
+	}
+	s.TotalRequests++
+	if r.late {
+		s.Late++
+	}
+	if r.err == nil && r.status >= 200 && r.status < 400 {
+		s.Successes++
+		s.Latencies.Record(r.latency)
+	} else {
+		s.Failures++
+		if r.latency > 0 {
+			s.Failed.Record(r.latency)
+		}
+	}
+}
+
+// StressResult holds the aggregated results of a stress test.
+type StressResult struct {
+	StressStats
+	Statuses map[int]*StatusStats   // responses by status code
+	Errors   map[string]*ErrorStats // requests without a response, by ErrClass*
+	Stages   []StageResult          // one per stage of a staged profile
+
+	precision int // of the per-status histograms
+}
+
+// record adds one sample to the totals and the status or error breakdown.
+func (sr *StressResult) record(r stressSample) {
+	sr.StressStats.record(r)
+	switch {
+	case r.dropped:
+	case r.err != nil:
+		class := ClassifyError(r.err)
+		e := sr.Errors[class]
+		if e == nil {
+			e = &ErrorStats{Sample: r.err.Error()}
+			sr.Errors[class] = e
+		}
+		e.Count++
+	default:
+		st := sr.Statuses[r.status]
+		if st == nil {
+			st = &StatusStats{Latencies: mustHistogram(sr.precision)}
+			sr.Statuses[r.status] = st
+		}
+		st.Count++
+		st.Latencies.Record(r.latency)
+	}
+}
+
+// StageResult holds the results of one stage of a staged profile.
+type StageResult struct {
+	Stage Stage
+	StressStats
 }
 
-// rateLimitedClient does a single HTTP request via a reusable client.
-var stressClient = &http.Client{}
+// stageTick is how often a staged closed loop adjusts its worker count.
+const stageTick = 100 * time.Millisecond
 
-// RunStress executes a load test against a URL using a goroutine worker pool.
-func RunStress(opts StressOptions) StressResult {
-	ctx, cancel := context.WithTimeout(context.Background(), opts.Duration+5*time.Second)
+// RunStress executes a load test against a URL. By default it is a closed
+// loop: Concurrency workers each send their next request as soon as the
+// previous one completes. With Rate set it is an open loop instead: requests
+// are scheduled at a fixed arrival rate regardless of how fast the server
+// answers, at most MaxInFlight at a time, and latency is measured from when
+// each request was due to be sent so that server stalls are not hidden
+// (coordinated omission). Stages ramp the worker count or arrival rate
+// linearly over time instead of holding it fixed.
+func RunStress(opts StressOptions) (StressResult, error) {
+	if _, err := NewHistogram(opts.precision()); err != nil {
+		return StressResult{}, err
+	}
+	transport, err := newTransport(transportKey{tls: opts.TLS, proxy: opts.Proxy, timeouts: opts.Timeouts})
+	if err != nil {
+		return StressResult{}, err
+	}
+	// Let every worker keep its connection alive between requests.
+	transport.MaxIdleConnsPerHost = max(opts.Concurrency, opts.MaxInFlight)
+	for _, s := range opts.Stages {
+		if !s.Rate {
+			transport.MaxIdleConnsPerHost = max(transport.MaxIdleConnsPerHost, int(s.Target))
+		}
+	}
+	stressClient := &http.Client{Transport: transport, Timeout: opts.Timeout}
+
+	// Give requests still in flight when the duration ends time to finish.
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Duration+max(opts.Timeout, 5*time.Second))
 	defer cancel()
 
-	type result struct {
-		latency time.Duration
-		err     error
-		status  int
+	start := time.Now()
+	var inFlight atomic.Int64
+	send := func(intended time.Time) stressSample {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		at := intended.Sub(start)
+		req, err := http.NewRequestWithContext(ctx, opts.Method, opts.URL, strings.NewReader(opts.Body))
+		if err != nil {
+			return stressSample{at: at, err: err}
+		}
+
+		if opts.Body != "" && req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for k, v := range opts.Headers {
+			req.Header.Set(k, v)
+		}
+		if opts.Auth != "" {
+			req.Header.Set("Authorization", AuthorizationValue(opts.Auth))
+		}
+
+		resp, err := stressClient.Do(req)
+		if err != nil {
+			return stressSample{at: at, latency: time.Since(intended), err: err}
+		}
+		_, err = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		latency := time.Since(intended)
+		if err != nil {
+			return stressSample{at: at, latency: latency, err: bodyReadError{err}}
+		}
+		return stressSample{at: at, latency: latency, status: resp.StatusCode}
 	}
 
-	resultCh := make(chan result, opts.Concurrency*10)
+	samples := make(chan stressSample, max(opts.Concurrency, opts.MaxInFlight)*10)
+	go func() {
+		if opts.openLoop() {
+			openLoop(ctx, opts, start, send, samples)
+		} else {
+			closedLoop(ctx, opts, start, send, samples)
+		}
+		close(samples)
+	}()
+
+	sr := StressResult{
+		StressStats: newStressStats(opts.precision()),
+		Statuses:    map[int]*StatusStats{},
+		Errors:      map[string]*ErrorStats{},
+		precision:   opts.breakdownPrecision(),
+	}
+	for _, s := range opts.Stages {
+		sr.Stages = append(sr.Stages, StageResult{Stage: s, StressStats: newStressStats(opts.breakdownPrecision())})
+	}
+
+	var (
+		progress *progressTracker
+		ticks    <-chan time.Time // stays nil without opts.Progress
+	)
+	if opts.Progress != nil {
+		progress = newProgressTracker(start)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	for {
+		select {
+		case r, ok := <-samples:
+			if !ok {
+				return sr, nil
+			}
+			sr.record(r)
+			if len(sr.Stages) > 0 {
+				sr.Stages[stageIndex(opts.Stages, r.at)].record(r)
+			}
+			if progress != nil {
+				progress.record(r)
+			}
+		case now := <-ticks:
+			opts.Progress(progress.tick(now, now.Sub(start), int(inFlight.Load())))
+		}
+	}
+}
+
+// stressSample is the outcome of a single request.
+type stressSample struct {
+	at      time.Duration // when the request was due, from the start of the test
+	latency time.Duration
+	err     error
+	status  int
+	dropped bool // never sent: too many requests in flight
+	late    bool
+}
+
+// closedLoop runs workers that each send back-to-back requests until the
+// duration or request budget runs out: Concurrency of them, or as many as
+// the current stage calls for.
+func closedLoop(ctx context.Context, opts StressOptions, start time.Time, send func(time.Time) stressSample, samples chan<- stressSample) {
 	var wg sync.WaitGroup
 	stop := make(chan struct{})
 
@@ -63,12 +283,14 @@ func RunStress(opts StressOptions) StressResult {
 		counter int
 	)
 
-	worker := func() {
+	worker := func(quit <-chan struct{}) {
 		defer wg.Done()
 		for {
 			select {
 			case <-stop:
 				return
+			case <-quit:
+				return
 			case <-ctx.Done():
 				return
 			default:
@@ -84,68 +306,139 @@ func RunStress(opts StressOptions) StressResult {
 				mu.Unlock()
 			}
 
-			req, err := http.NewRequestWithContext(ctx, opts.Method, opts.URL, strings.NewReader(opts.Body))
-			if err != nil {
-				resultCh <- result{err: err}
-				continue
-			}
+			samples <- send(time.Now())
+		}
+	}
 
-			if opts.Body != "" && req.Header.Get("Content-Type") == "" {
-				req.Header.Set("Content-Type", "application/json")
-			}
-			for k, v := range opts.Headers {
-				req.Header.Set(k, v)
-			}
-			if opts.Auth != "" {
-				if strings.HasPrefix(opts.Auth, "Bearer ") || strings.HasPrefix(opts.Auth, "Basic ") {
-					req.Header.Set("Authorization", opts.Auth)
-				} else {
-					req.Header.Set("Authorization", "Bearer "+opts.Auth)
-				}
-			}
+	if len(opts.Stages) == 0 {
+		for i := 0; i < opts.Concurrency; i++ {
+			wg.Add(1)
+			go worker(nil)
+		}
+		wg.Wait()
+		return
+	}
 
-			start := time.Now()
-			resp, err := stressClient.Do(req)
-			if err != nil {
-				resultCh <- result{latency: time.Since(start), err: err}
-				continue
-			}
-			latency := time.Since(start)
-			resp.Body.Close()
+	// Follow the ramp, starting and stopping workers as the target moves.
+	var quits []chan struct{}
+	ticker := time.NewTicker(stageTick)
+	defer ticker.Stop()
+	for running := true; running; {
+		want := int(math.Round(stageTarget(opts.Stages, time.Since(start))))
+		for len(quits) < want {
+			quit := make(chan struct{})
+			quits = append(quits, quit)
+			wg.Add(1)
+			go worker(quit)
+		}
+		for len(quits) > want {
+			close(quits[len(quits)-1])
+			quits = quits[:len(quits)-1]
+		}
 
-			resultCh <- result{latency: latency, status: resp.StatusCode}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			running = false
+		case <-ctx.Done():
+			running = false
 		}
 	}
+	wg.Wait()
+}
 
-	for i := 0; i < opts.Concurrency; i++ {
-		wg.Add(1)
-		go worker()
+// openLoop schedules requests at opts.Rate per second, or along the staged
+// rate ramp, until the duration or request budget runs out. A request that
+// comes due while MaxInFlight are already outstanding is dropped rather than
+// queued; one sent more than an interval (at least 1ms) after it was due is
+// counted as late.
+func openLoop(ctx context.Context, opts StressOptions, start time.Time, send func(time.Time) stressSample, samples chan<- stressSample) {
+	due := func(i int) (time.Duration, bool) {
+		at := time.Duration(float64(i) * float64(time.Second) / opts.Rate)
+		return at, at < opts.Duration
+	}
+	if len(opts.Stages) > 0 {
+		due = func(i int) (time.Duration, bool) { return stageSendOffset(opts.Stages, i) }
 	}
 
-	// Close the result channel once all workers finish
-	go func() {
-		wg.Wait()
-		close(resultCh)
-	}()
+	slots := make(chan struct{}, max(opts.MaxInFlight, 1))
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
-	var sr StressResult
-	for r := range resultCh {
-		sr.TotalRequests++
-		if r.err != nil {
-			sr.Failures++
-			errMsg := r.err.Error()
-			if len(sr.Errors) < 5 { // store only first 5 unique errors
-				sr.Errors = append(sr.Errors, errMsg)
+	var prev time.Duration
+	for i := 0; opts.MaxRequests <= 0 || i < opts.MaxRequests; i++ {
+		at, ok := due(i)
+		if !ok {
+			return
+		}
+		intended := start.Add(at)
+		lateAfter := max(at-prev, time.Millisecond)
+		prev = at
+
+		if wait := time.Until(intended); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return
 			}
-		} else if r.status >= 200 && r.status < 400 {
-			sr.Successes++
-			sr.Latencies = append(sr.Latencies, r.latency)
-		} else {
-			sr.Failures++
 		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			samples <- stressSample{at: at, dropped: true}
+			continue
+		}
+		late := time.Since(intended) > lateAfter
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := send(intended)
+			r.late = late
+			samples <- r
+			<-slots
+		}()
 	}
+}
+
+// FormatRate renders requests per second the way ParseRate accepts them.
+func FormatRate(rps float64) string {
+	return strconv.FormatFloat(rps, 'f', -1, 64) + "/s"
+}
 
-	return sr
+// ParseRate parses an arrival rate such as "500/s", "30/m", "10/100ms" or
+// a bare "500" (per second) into requests per second.
+func ParseRate(s string) (float64, error) {
+	rate, err := parseRate(s)
+	if err == nil && rate == 0 {
+		err = fmt.Errorf("invalid rate %q: must be positive", s)
+	}
+	return rate, err
+}
+
+// parseRate is ParseRate allowing a rate of zero, as ramps may start or end
+// there.
+func parseRate(s string) (float64, error) {
+	count, per, hasUnit := strings.Cut(strings.TrimSpace(s), "/")
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid rate %q (want e.g. 500/s, 30/m or 500)", s)
+	}
+	if !hasUnit {
+		return n, nil
+	}
+	if per == "s" || per == "m" || per == "h" {
+		per = "1" + per
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid rate %q (want e.g. 500/s, 30/m or 500)", s)
+	}
+	return n / d.Seconds(), nil
 }
 
 // PrintStressReport prints a formatted summary report to stdout.
@@ -153,54 +446,90 @@ func PrintStressReport(opts StressOptions, result StressResult) {
 	fmt.Println()
 	fmt.Println("════════════════════ STRESS TEST REPORT ════════════════════")
 	fmt.Printf("  Target:       %s %s\n", opts.Method, opts.URL)
-	fmt.Printf("  Concurrency:  %d workers\n", opts.Concurrency)
+	switch {
+	case len(opts.Stages) > 0:
+		kind := "workers"
+		if opts.openLoop() {
+			kind = fmt.Sprintf("arrival rate, max %d in flight", opts.MaxInFlight)
+		}
+		fmt.Printf("  Stages:       %d (%s)\n", len(opts.Stages), kind)
+	case opts.Rate > 0:
+		fmt.Printf("  Rate:         %s (max %d in flight)\n", FormatRate(opts.Rate), opts.MaxInFlight)
+	default:
+		fmt.Printf("  Concurrency:  %d workers\n", opts.Concurrency)
+	}
 	fmt.Printf("  Duration:     %s\n", opts.Duration)
 	fmt.Println("────────────────────────────────────────────────────────────")
 	fmt.Printf("  Total Reqs:   %d\n", result.TotalRequests)
 	fmt.Printf("  Successes:    %d\n", result.Successes)
 	fmt.Printf("  Failures:     %d\n", result.Failures)
+	if opts.openLoop() {
+		fmt.Printf("  Dropped:      %d\n", result.Dropped)
+		fmt.Printf("  Late:         %d\n", result.Late)
+	}
 	if result.TotalRequests > 0 && opts.Duration > 0 {
 		rps := float64(result.TotalRequests) / opts.Duration.Seconds()
 		fmt.Printf("  Req/sec:      %.2f\n", rps)
 	}
 
-	if len(result.Latencies) > 0 {
-		sort.Slice(result.Latencies, func(i, j int) bool {
-			return result.Latencies[i] < result.Latencies[j]
-		})
-		n := len(result.Latencies)
-		var total time.Duration
-		for _, l := range result.Latencies {
-			total += l
-		}
-		avg := total / time.Duration(n)
-
+	if h := result.Latencies; h.Count() > 0 {
 		fmt.Println("────────────────────────────────────────────────────────────")
-		fmt.Printf("  Latency Min:  %v\n", result.Latencies[0])
-		fmt.Printf("  Latency Max:  %v\n", result.Latencies[n-1])
-		fmt.Printf("  Latency Avg:  %v\n", avg)
-		fmt.Printf("  Latency P50:  %v\n", result.Latencies[percentileIdx(n, 50)])
-		fmt.Printf("  Latency P95:  %v\n", result.Latencies[percentileIdx(n, 95)])
-		fmt.Printf("  Latency P99:  %v\n", result.Latencies[percentileIdx(n, 99)])
+		fmt.Printf("  Latency Min:    %v\n", h.Min())
+		fmt.Printf("  Latency Max:    %v\n", h.Max())
+		fmt.Printf("  Latency Avg:    %v\n", h.Mean())
+		for _, p := range []float64{50, 90, 95, 99, 99.9, 99.99} {
+			fmt.Printf("  Latency P%-6s %v\n", strconv.FormatFloat(p, 'f', -1, 64)+":", h.Percentile(p))
+		}
+	}
+	if h := result.Failed; h.Count() > 0 {
+		p := latencyPercentiles(h, 50, 99)
+		fmt.Printf("  Failed Reqs:    P50 %s  P99 %s  Max %v\n", p[0], p[1], h.Max())
 	}
 
+	if len(result.Statuses) > 0 {
+		printStatusTable(result.Statuses, result.TotalRequests)
+	}
 	if len(result.Errors) > 0 {
-		fmt.Println("────────────────────────────────────────────────────────────")
-		fmt.Println("  Sample Errors:")
-		for _, e := range result.Errors {
-			fmt.Printf("    • %s\n", e)
-		}
+		printErrorTable(result.Errors, result.TotalRequests)
+	}
+	if len(result.Stages) > 0 {
+		printStages(result.Stages, opts.openLoop())
 	}
+
 	fmt.Println("════════════════════════════════════════════════════════════")
 }
 
-func percentileIdx(n int, p float64) int {
-	idx := int(math.Ceil(float64(n)*p/100)) - 1
-	if idx < 0 {
-		idx = 0
+// printStages prints one row per stage of a staged profile.
+func printStages(stages []StageResult, openLoop bool) {
+	fmt.Println("────────────────────────────────────────────────────────────")
+	fmt.Println("  Per Stage:")
+	fmt.Printf("    %-3s %-12s %-9s %-14s %7s %7s %6s", "#", "NAME", "DURATION", "TARGET", "REQS", "OK", "FAIL")
+	if openLoop {
+		fmt.Printf(" %6s", "DROP")
 	}
-	if idx >= n {
-		idx = n - 1
+	fmt.Printf(" %10s %10s %10s\n", "P50", "P95", "P99")
+
+	from := "0"
+	for i, st := range stages {
+		to := strconv.FormatFloat(st.Stage.Target, 'f', -1, 64)
+		if st.Stage.Rate {
+			to = FormatRate(st.Stage.Target)
+		}
+		name := st.Stage.Name
+		if name == "" {
+			name = "-"
+		}
+		target := from + "→" + to
+		// Pad by characters, not bytes, because of the arrow.
+		fmt.Printf("    %-3d %-12s %-9s %-*s %7d %7d %6d", i+1, name, st.Stage.Duration,
+			14+len(target)-utf8.RuneCountInString(target), target, st.TotalRequests, st.Successes, st.Failures)
+		if openLoop {
+			fmt.Printf(" %6d", st.Dropped)
+		}
+		for _, cell := range latencyPercentiles(st.Latencies, 50, 95, 99) {
+			fmt.Printf(" %10s", cell)
+		}
+		fmt.Println()
+		from = strings.TrimSuffix(to, "/s")
 	}
-	return idx
 }
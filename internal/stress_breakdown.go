@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Error classes reported by a stress test for requests that got no usable
+// response.
+const (
+	ErrClassTimeout = "timeout"
+	ErrClassRefused = "connection refused"
+	ErrClassReset   = "connection reset"
+	ErrClassTLS     = "tls"
+	ErrClassDNS     = "dns"
+	ErrClassBody    = "body read"
+	ErrClassOther   = "other"
+)
+
+// StatusStats are the responses received with one status code.
+type StatusStats struct {
+	Count     int
+	Latencies *Histogram
+}
+
+// ErrorStats are the failed requests of one error class.
+type ErrorStats struct {
+	Count  int
+	Sample string // the first error seen, for context
+}
+
+// bodyReadError marks a failure reading a response body, after the status
+// and headers arrived.
+type bodyReadError struct{ err error }
+
+func (e bodyReadError) Error() string { return "reading response body: " + e.err.Error() }
+func (e bodyReadError) Unwrap() error { return e.err }
+
+// ClassifyError sorts a request error into one of the ErrClass* classes.
+func ClassifyError(err error) string {
+	var (
+		bodyErr   bodyReadError
+		dnsErr    *net.DNSError
+		netErr    net.Error
+		recordErr tls.RecordHeaderError
+		alertErr  tls.AlertError
+		verifyErr *tls.CertificateVerificationError
+		authErr   x509.UnknownAuthorityError
+		hostErr   x509.HostnameError
+		certErr   x509.CertificateInvalidError
+	)
+	switch {
+	case errors.As(err, &bodyErr):
+		return ErrClassBody
+	case errors.As(err, &dnsErr):
+		return ErrClassDNS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrClassTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrClassRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrClassReset
+	case errors.As(err, &recordErr), errors.As(err, &alertErr), errors.As(err, &verifyErr),
+		errors.As(err, &authErr), errors.As(err, &hostErr), errors.As(err, &certErr),
+		strings.Contains(err.Error(), "tls: "):
+		return ErrClassTLS
+	}
+	return ErrClassOther
+}
+
+// printStatusTable prints response counts and latency percentiles per
+// status code.
+func printStatusTable(statuses map[int]*StatusStats, total int) {
+	codes := make([]int, 0, len(statuses))
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	fmt.Println("────────────────────────────────────────────────────────────")
+	fmt.Println("  Status Codes:")
+	fmt.Printf("    %-6s %7s %7s %10s %10s %10s\n", "CODE", "COUNT", "%", "P50", "P95", "P99")
+	for _, code := range codes {
+		st := statuses[code]
+		fmt.Printf("    %-6d %7d %6.1f%%", code, st.Count, percentOf(st.Count, total))
+		for _, d := range latencyPercentiles(st.Latencies, 50, 95, 99) {
+			fmt.Printf(" %10s", d)
+		}
+		fmt.Println()
+	}
+}
+
+// printErrorTable prints failed requests per error class, most common
+// first, each with a sample message.
+func printErrorTable(errs map[string]*ErrorStats, total int) {
+	classes := make([]string, 0, len(errs))
+	for class := range errs {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if errs[classes[i]].Count != errs[classes[j]].Count {
+			return errs[classes[i]].Count > errs[classes[j]].Count
+		}
+		return classes[i] < classes[j]
+	})
+
+	fmt.Println("────────────────────────────────────────────────────────────")
+	fmt.Println("  Errors:")
+	fmt.Printf("    %-20s %7s %7s\n", "CLASS", "COUNT", "%")
+	for _, class := range classes {
+		e := errs[class]
+		fmt.Printf("    %-20s %7d %6.1f%%\n", class, e.Count, percentOf(e.Count, total))
+		fmt.Printf("      • %s\n", e.Sample)
+	}
+}
+
+// latencyPercentiles returns the given percentiles, rounded for display, or
+// "-" when nothing was recorded.
+func latencyPercentiles(h *Histogram, ps ...float64) []string {
+	cells := make([]string, len(ps))
+	for i, p := range ps {
+		cells[i] = "-"
+		if h.Count() > 0 {
+			cells[i] = h.Percentile(p).Round(time.Microsecond).String()
+		}
+	}
+	return cells
+}
+
+func percentOf(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
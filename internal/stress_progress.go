@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	progressInterval = time.Second
+	progressWindow   = 5  // seconds of latencies behind the rolling percentiles
+	progressHistory  = 30 // seconds of throughput in the sparkline
+
+	// progressPrecision is the histogram precision of the rolling
+	// percentiles, whatever --hdr-precision says: plenty for a live display,
+	// and small enough (about 27KB per histogram) to merge every second.
+	progressPrecision = 2
+
+	// plainProgressEvery spaces out progress lines when stdout is not a
+	// terminal, so CI logs stay readable on long runs.
+	plainProgressEvery = 5 * time.Second
+)
+
+// StressProgress is a snapshot of a running stress test, taken every
+// second.
+type StressProgress struct {
+	Elapsed       time.Duration
+	Total         int     // requests completed so far
+	Failures      int     // failed requests so far
+	InFlight      int     // requests sent and not yet answered
+	RPS           float64 // requests completed per second over the last second
+	ErrorRate     float64 // percentage of requests that failed over the last second
+	P50, P95, P99 time.Duration
+	Throughput    []float64 // requests per second for each recent second, oldest first
+}
+
+// progressTracker keeps the rolling numbers behind StressProgress. It is
+// only used from the goroutine collecting samples.
+type progressTracker struct {
+	total, failures int
+	completed       int // in the current second
+	failed          int // in the current second
+	window          []*Histogram
+	current         int // index into window
+	merged          *Histogram
+	throughput      []float64
+	lastTick        time.Time
+}
+
+func newProgressTracker(start time.Time) *progressTracker {
+	t := &progressTracker{merged: mustHistogram(progressPrecision), lastTick: start}
+	for range progressWindow {
+		t.window = append(t.window, mustHistogram(progressPrecision))
+	}
+	return t
+}
+
+func (t *progressTracker) record(r stressSample) {
+	if r.dropped {
+		return
+	}
+	t.total++
+	t.completed++
+	if r.err == nil && r.status >= 200 && r.status < 400 {
+		t.window[t.current].Record(r.latency)
+	} else {
+		t.failures++
+		t.failed++
+	}
+}
+
+// tick returns a snapshot and starts the next second.
+func (t *progressTracker) tick(now time.Time, elapsed time.Duration, inFlight int) StressProgress {
+	rps := 0.0
+	if secs := now.Sub(t.lastTick).Seconds(); secs > 0 {
+		rps = float64(t.completed) / secs
+	}
+	t.throughput = append(t.throughput, rps)
+	if len(t.throughput) > progressHistory {
+		t.throughput = t.throughput[1:]
+	}
+
+	t.merged.Reset()
+	for _, h := range t.window {
+		t.merged.Merge(h)
+	}
+
+	p := StressProgress{
+		Elapsed:    elapsed,
+		Total:      t.total,
+		Failures:   t.failures,
+		InFlight:   inFlight,
+		RPS:        rps,
+		ErrorRate:  percentOf(t.failed, t.completed),
+		P50:        t.merged.Percentile(50),
+		P95:        t.merged.Percentile(95),
+		P99:        t.merged.Percentile(99),
+		Throughput: append([]float64(nil), t.throughput...),
+	}
+
+	t.completed, t.failed = 0, 0
+	t.lastTick = now
+	t.current = (t.current + 1) % len(t.window)
+	t.window[t.current].Reset()
+	return p
+}
+
+// StressDisplay shows StressProgress while a stress test runs: a panel
+// redrawn in place on a terminal, or a plain line every few seconds when
+// output goes to a pipe or file (the web terminal, CI logs).
+type StressDisplay struct {
+	w        io.Writer
+	live     bool
+	duration time.Duration // 0 when the test is bounded by a request count
+	drawn    int           // lines of the last live panel
+	nextLine time.Duration
+}
+
+// NewStressDisplay returns a display writing to f, live if f is a terminal.
+func NewStressDisplay(f *os.File, duration time.Duration) *StressDisplay {
+	return &StressDisplay{w: f, live: isTerminal(f), duration: duration, nextLine: plainProgressEvery}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Update shows a new snapshot.
+func (d *StressDisplay) Update(p StressProgress) {
+	if d.live {
+		d.drawPanel(p)
+		return
+	}
+	if p.Elapsed < d.nextLine {
+		return
+	}
+	d.nextLine += plainProgressEvery
+	fmt.Fprintf(d.w, "  [%6s] %8.1f req/s | in flight %d | p50 %s p95 %s p99 %s | errors %.1f%% | %d total\n",
+		p.Elapsed.Round(time.Second), p.RPS, p.InFlight,
+		roundLatency(p.P50), roundLatency(p.P95), roundLatency(p.P99), p.ErrorRate, p.Total)
+}
+
+func (d *StressDisplay) drawPanel(p StressProgress) {
+	elapsed := p.Elapsed.Round(time.Second).String()
+	if d.duration > 0 {
+		elapsed += " / " + d.duration.String()
+	}
+	lines := []string{
+		fmt.Sprintf("  ⏱  Elapsed:    %s", elapsed),
+		fmt.Sprintf("  🚀 Req/sec:    %.1f   In Flight: %d   Total: %d", p.RPS, p.InFlight, p.Total),
+		fmt.Sprintf("  📊 Latency:    P50 %s   P95 %s   P99 %s   (last %ds)",
+			roundLatency(p.P50), roundLatency(p.P95), roundLatency(p.P99), progressWindow),
+		fmt.Sprintf("  ❌ Errors:     %.1f%% (last 1s)   %d total", p.ErrorRate, p.Failures),
+		fmt.Sprintf("  📈 Throughput: %s", sparkline(p.Throughput)),
+	}
+
+	var b strings.Builder
+	if d.drawn > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", d.drawn) // back to the top of the last panel
+	}
+	for _, l := range lines {
+		b.WriteString("\x1b[2K" + l + "\n")
+	}
+	io.WriteString(d.w, b.String())
+	d.drawn = len(lines)
+}
+
+func roundLatency(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Microsecond).String()
+}
+
+// sparkline draws values as block characters scaled to the largest.
+func sparkline(values []float64) string {
+	const bars = "▁▂▃▄▅▆▇█"
+	levels := []rune(bars)
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if peak > 0 {
+			i = int(v / peak * float64(len(levels)-1))
+		}
+		b.WriteRune(levels[i])
+	}
+	return b.String()
+}
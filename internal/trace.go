@@ -0,0 +1,245 @@
+package internal
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestTrace records what went over the wire for a single request and how
+// long each connection phase took. Pass one in RequestOptions.Trace to have
+// SendRequest fill it in.
+type RequestTrace struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	done         time.Time
+
+	// Reused reports whether the request went over a kept-alive connection.
+	Reused bool
+	// RemoteAddr is the address of the server the request was sent to.
+	RemoteAddr string
+	// RequestHeaders are the header fields as written to the connection,
+	// including those the transport adds (Host, User-Agent, ...).
+	RequestHeaders [][2]string
+	// Redirects lists every redirect that was followed, in order.
+	Redirects []RedirectHop
+
+	hopStart time.Time
+}
+
+// RedirectHop describes one redirect response that was followed.
+type RedirectHop struct {
+	Method   string
+	URL      string
+	Status   string
+	Location string
+	Duration time.Duration
+}
+
+// clientTrace returns the httptrace hooks that populate t.
+func (t *RequestTrace) clientTrace() *httptrace.ClientTrace {
+	now := func(field *time.Time) {
+		t.mu.Lock()
+		*field = time.Now()
+		t.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			// Each redirect hop starts over; keep only the last one.
+			t.mu.Lock()
+			t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+			t.connectStart, t.connectDone = time.Time{}, time.Time{}
+			t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+			t.wroteRequest, t.firstByte = time.Time{}, time.Time{}
+			t.RequestHeaders = nil
+			t.mu.Unlock()
+		},
+		DNSStart:          func(httptrace.DNSStartInfo) { now(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { now(&t.dnsDone) },
+		ConnectStart:      func(string, string) { now(&t.connectStart) },
+		ConnectDone:       func(string, string, error) { now(&t.connectDone) },
+		TLSHandshakeStart: func() { now(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { now(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.Reused = info.Reused
+			if info.Conn != nil {
+				t.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
+			t.mu.Unlock()
+		},
+		WroteHeaderField: func(key string, value []string) {
+			t.mu.Lock()
+			for _, v := range value {
+				t.RequestHeaders = append(t.RequestHeaders, [2]string{key, v})
+			}
+			t.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { now(&t.wroteRequest) },
+		GotFirstResponseByte: func() { now(&t.firstByte) },
+	}
+}
+
+// begin resets the trace for a new attempt starting at start.
+func (t *RequestTrace) begin(start time.Time) {
+	t.mu.Lock()
+	t.start = start
+	t.hopStart = time.Time{}
+	t.Redirects = nil
+	t.mu.Unlock()
+}
+
+// addRedirect records a redirect response received for req.
+func (t *RequestTrace) addRedirect(req *http.Request, resp *http.Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	from := t.hopStart
+	if from.IsZero() {
+		from = t.start
+	}
+	t.hopStart = now
+
+	hop := RedirectHop{Method: req.Method, URL: req.URL.String(), Duration: now.Sub(from)}
+	if resp != nil {
+		hop.Status = resp.Status
+		hop.Location = resp.Header.Get("Location")
+	}
+	t.Redirects = append(t.Redirects, hop)
+}
+
+// finish records the end of the request.
+func (t *RequestTrace) finish() {
+	t.mu.Lock()
+	t.done = time.Now()
+	t.mu.Unlock()
+}
+
+// span returns the time between two phase timestamps, or zero if either
+// phase did not happen.
+func span(from, to time.Time) time.Duration {
+	if from.IsZero() || to.IsZero() {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+// PrintRequestTrace prints the outgoing request line and the header fields
+// written to the connection, curl -v style.
+func PrintRequestTrace(t *RequestTrace, method, url string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fmt.Printf("> %s %s\n", method, url)
+	for _, h := range t.RequestHeaders {
+		fmt.Printf("> %s: %s\n", h[0], h[1])
+	}
+	fmt.Println(">")
+}
+
+// PrintResponseHeaders prints the response status line and every response
+// header in sorted order.
+func PrintResponseHeaders(resp *http.Response) {
+	fmt.Printf("< %s %s\n", resp.Proto, resp.Status)
+	keys := make([]string, 0, len(resp.Header))
+	for k := range resp.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range resp.Header[k] {
+			fmt.Printf("< %s: %s\n", k, v)
+		}
+	}
+	fmt.Println("<")
+}
+
+// PrintRedirects prints each followed redirect with its status, target and
+// how long the hop took.
+func PrintRedirects(t *RequestTrace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.Redirects) == 0 {
+		return
+	}
+	fmt.Println("Redirects:")
+	for i, h := range t.Redirects {
+		fmt.Printf("  %d. %s %s\n", i+1, h.Method, h.URL)
+		fmt.Printf("     %s → %s  (%v)\n", h.Status, h.Location, h.Duration.Round(time.Microsecond))
+	}
+	fmt.Println()
+}
+
+// PrintTLSState prints the negotiated TLS version and cipher suite and the
+// certificate chain presented by the server.
+func PrintTLSState(state *tls.ConnectionState) {
+	fmt.Println("TLS:")
+	fmt.Printf("  Version:     %s\n", tls.VersionName(state.Version))
+	fmt.Printf("  Cipher:      %s\n", tls.CipherSuiteName(state.CipherSuite))
+	if state.NegotiatedProtocol != "" {
+		fmt.Printf("  ALPN:        %s\n", state.NegotiatedProtocol)
+	}
+	if state.ServerName != "" {
+		fmt.Printf("  Server name: %s\n", state.ServerName)
+	}
+	if len(state.PeerCertificates) > 0 {
+		fmt.Println("  Certificate chain:")
+	}
+	for i, cert := range state.PeerCertificates {
+		fmt.Printf("    %d  %s\n", i, cert.Subject)
+		fmt.Printf("       issuer:  %s\n", cert.Issuer)
+		fmt.Printf("       expires: %s\n", cert.NotAfter.Format("2006-01-02"))
+		if i == 0 && len(cert.DNSNames) > 0 {
+			fmt.Printf("       names:   %s\n", strings.Join(cert.DNSNames, ", "))
+		}
+	}
+	fmt.Println()
+}
+
+// PrintTiming prints the connection timing breakdown, similar to httpstat.
+func PrintTiming(t *RequestTrace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	phase := func(label string, d time.Duration, happened bool) {
+		if !happened {
+			fmt.Printf("  %-19s -\n", label+":")
+			return
+		}
+		fmt.Printf("  %-19s %v\n", label+":", d.Round(time.Microsecond))
+	}
+
+	fmt.Println("Timing:")
+	phase("DNS lookup", span(t.dnsStart, t.dnsDone), !t.dnsDone.IsZero())
+	phase("TCP connect", span(t.connectStart, t.connectDone), !t.connectDone.IsZero())
+	phase("TLS handshake", span(t.tlsStart, t.tlsDone), !t.tlsDone.IsZero())
+	phase("Server processing", span(t.wroteRequest, t.firstByte), !t.firstByte.IsZero())
+	phase("Time to first byte", span(t.start, t.firstByte), !t.firstByte.IsZero())
+	phase("Content transfer", span(t.firstByte, t.done), !t.firstByte.IsZero() && !t.done.IsZero())
+	phase("Total", span(t.start, t.done), !t.done.IsZero())
+
+	conn := "new"
+	if t.Reused {
+		conn = "reused"
+	}
+	if t.RemoteAddr != "" {
+		conn += " (" + t.RemoteAddr + ")"
+	}
+	fmt.Printf("  %-19s %s\n", "Connection:", conn)
+}
@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TLSOptions controls how connections verify the server and which client
+// certificate, if any, they present.
+type TLSOptions struct {
+	CACert     string `json:"cacert,omitempty"`      // PEM bundle trusted in addition to the system roots
+	Cert       string `json:"cert,omitempty"`        // client certificate (PEM), may also contain the key
+	Key        string `json:"key,omitempty"`         // client private key (PEM)
+	Insecure   bool   `json:"insecure,omitempty"`    // skip server certificate verification
+	MinVersion string `json:"min_version,omitempty"` // "1.0", "1.1", "1.2" or "1.3"
+	ServerName string `json:"servername,omitempty"`  // SNI and verification name override
+}
+
+// Merge returns o with every field that is set in override replacing its
+// counterpart.
+func (o TLSOptions) Merge(override TLSOptions) TLSOptions {
+	if override.CACert != "" {
+		o.CACert = override.CACert
+	}
+	if override.Cert != "" {
+		o.Cert = override.Cert
+	}
+	if override.Key != "" {
+		o.Key = override.Key
+	}
+	if override.Insecure {
+		o.Insecure = true
+	}
+	if override.MinVersion != "" {
+		o.MinVersion = override.MinVersion
+	}
+	if override.ServerName != "" {
+		o.ServerName = override.ServerName
+	}
+	return o
+}
+
+// tlsVersions maps --tls-min-version values to crypto/tls constants.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Config builds a tls.Config from the options, loading any certificate
+// files they reference. It returns nil when no option is set.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	if o == (TLSOptions{}) {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		InsecureSkipVerify: o.Insecure,
+		ServerName:         o.ServerName,
+	}
+
+	if o.MinVersion != "" {
+		v, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(o.MinVersion), "tls")]
+		if !ok {
+			return nil, fmt.Errorf("invalid TLS min version %q (want 1.0, 1.1, 1.2 or 1.3)", o.MinVersion)
+		}
+		cfg.MinVersion = v
+	}
+
+	if o.CACert != "" {
+		if err := CheckLocalFileRead("--cacert "+o.CACert, o.CACert); err != nil {
+			return nil, err
+		}
+		pem, err := os.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %q", o.CACert)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.Cert != "" {
+		key := o.Key
+		if key == "" {
+			key = o.Cert
+		}
+		for _, f := range []string{o.Cert, key} {
+			if err := CheckLocalFileRead("client certificate "+f, f); err != nil {
+				return nil, err
+			}
+		}
+		cert, err := tls.LoadX509KeyPair(o.Cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	} else if o.Key != "" {
+		return nil, fmt.Errorf("a client key was given without a certificate")
+	}
+
+	return cfg, nil
+}
+
+// Timeouts bounds the individual phases of a request, on top of the overall
+// request timeout. Zero fields keep Go's defaults: 30s to connect, 10s for
+// the TLS handshake, and no limit on waiting for response headers.
+type Timeouts struct {
+	Connect        time.Duration `json:"connect_ns,omitempty"` // TCP connect, including to a proxy
+	TLSHandshake   time.Duration `json:"tls_handshake_ns,omitempty"`
+	ResponseHeader time.Duration `json:"response_header_ns,omitempty"` // from the request being written to the response headers arriving
+}
+
+// transportKey identifies a distinct transport configuration.
+type transportKey struct {
+	tls      TLSOptions
+	proxy    string
+	timeouts Timeouts
+}
+
+var (
+	transportsMu sync.Mutex
+	transports   = map[transportKey]*http.Transport{}
+)
+
+// transportFor returns a shared transport for the given options so that
+// consecutive requests with the same settings reuse connections.
+func transportFor(key transportKey) (*http.Transport, error) {
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+
+	if t, ok := transports[key]; ok {
+		return t, nil
+	}
+	t, err := newTransport(key)
+	if err != nil {
+		return nil, err
+	}
+	transports[key] = t
+	return t, nil
+}
+
+// newTransport builds a transport from Go's defaults plus the given options.
+func newTransport(key transportKey) (*http.Transport, error) {
+	cfg, err := key.tls.Config()
+	if err != nil {
+		return nil, err
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg != nil {
+		t.TLSClientConfig = cfg
+	}
+	if key.proxy != "" {
+		u, err := parseProxyURL(key.proxy)
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	if d := key.timeouts.Connect; d > 0 {
+		dialer := &net.Dialer{Timeout: d, KeepAlive: 30 * time.Second}
+		t.DialContext = dialer.DialContext
+	}
+	if d := key.timeouts.TLSHandshake; d > 0 {
+		t.TLSHandshakeTimeout = d
+	}
+	if d := key.timeouts.ResponseHeader; d > 0 {
+		t.ResponseHeaderTimeout = d
+	}
+	return t, nil
+}
+
+// CheckTransport validates TLS and proxy settings up front, so that
+// configuration mistakes are reported before any request is sent.
+func CheckTransport(tlsOpts TLSOptions, proxy string) error {
+	_, err := newTransport(transportKey{tls: tlsOpts, proxy: proxy})
+	return err
+}
+
+// parseProxyURL parses a proxy address. A bare host:port is treated as an
+// HTTP proxy; credentials may be given as user:pass@host.
+func parseProxyURL(s string) (*url.URL, error) {
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (want http, https or socks5)", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", s)
+	}
+	return u, nil
+}
@@ -148,6 +148,10 @@ func wsHandler(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 
 		cmd := exec.CommandContext(ctx, binaryPath, parts...)
+		// Visitors must not be able to send the server's own files anywhere
+		// (--body @file, -F name=@file, ...) or overwrite them; only the
+		// demo environment file may be read.
+		cmd.Env = append(os.Environ(), "APITESTER_NO_LOCAL_FILES=1", "APITESTER_ALLOW_FILES=demo-env.json")
 		output, err := cmd.CombinedOutput()
 
 		// Convert newlines for the terminal (\n -> \r\n).